@@ -1,9 +1,16 @@
 package kubo
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
+
+	"github.com/ravernkoh/kubo/kuboutil"
 )
 
 // App represents a command line app.
@@ -12,159 +19,659 @@ type App struct {
 
 	Stdin  io.Reader // default is os.Stdin
 	Stdout io.Writer // default is os.Stdout
+	Stderr io.Writer // default is os.Stderr
+
+	// DisableDefaultHelpFlag is whether to stop handling '--help' and '-h'
+	// on every command.
+	//
+	// By default, passing either of them prints the usage details of the
+	// command instead of running it, unless the command defines a flag with
+	// the same name. Help is then only available through an explicitly added
+	// help command.
+	DisableDefaultHelpFlag bool
+
+	// CommandNotFoundFunc is called with the context of the current command
+	// and the typed name when a child command is not found.
+	//
+	// If it returns nil, the app treats the command as handled (e.g. by
+	// running an external command) and returns nil. Otherwise, the error
+	// returned is propagated. If it is nil, the command not found error is
+	// returned.
+	CommandNotFoundFunc func(ctx *Context, name string) error
+
+	// OnCommandNotFound is called with the context of the current command and
+	// the typed name when a child command is not found, before
+	// CommandNotFoundFunc.
+	//
+	// Unlike CommandNotFoundFunc, it can't change how the app handles the
+	// command not being found, which makes it suitable for observing the
+	// event (e.g. for metrics).
+	OnCommandNotFound func(ctx *Context, name string)
+
+	// CommandNotFoundObservers are called in order after OnCommandNotFound,
+	// to allow multiple observers to be registered.
+	CommandNotFoundObservers []func(ctx *Context, name string)
+
+	// PreParsing is called with the arguments passed to Run (without the
+	// program name) before they are parsed, and returns the arguments that
+	// are parsed instead (e.g. with aliases expanded).
+	PreParsing func(args []string) []string
+
+	// Lifecycle holds the hooks run when the command starts and stops.
+	Lifecycle Lifecycle
+
+	// CleanupOnError is whether to run the After hooks of the command and its
+	// ancestors even if a Before hook returns an error or a hook or Run
+	// panics, like deferred calls. A panic is propagated once they are run.
+	//
+	// By default, the After hooks are only run if all the Before hooks
+	// succeed.
+	CleanupOnError bool
+
+	// SortCommands is whether to sort child commands by name in the usage
+	// details and shell completion, instead of listing them in the order
+	// they were added. NewApp sets this to true.
+	SortCommands bool
+
+	// SortFlags is whether to sort flags by name in the usage details and
+	// shell completion, instead of listing them in the order they were
+	// defined. NewApp sets this to true.
+	SortFlags bool
+
+	// HideGlobalFlags is whether to leave out the flags inherited from the
+	// ancestors of a command in its usage details, which are still listed
+	// in the usage details of the commands defining them (e.g. the root
+	// command).
+	HideGlobalFlags bool
+
+	// FlagInterspersed is whether flags can be passed after arguments (e.g.
+	// 'command argument --flag').
+	//
+	// If it is false, flags are only parsed until the first argument, as
+	// POSIX requires, and everything after it is parsed as arguments. NewApp
+	// sets this to true.
+	FlagInterspersed bool
+
+	// EnableCaseInsensitiveCommands is whether to resolve child commands by
+	// their names and aliases case-insensitively (e.g. 'STATUS' runs the
+	// 'status' command) if there is no exact match.
+	EnableCaseInsensitiveCommands bool
+
+	// EnableAbbreviations is whether to resolve child commands by a prefix
+	// of their names or aliases (e.g. 'serv' runs the 'server' command) if
+	// there is no exact match.
+	//
+	// If more than one child command matches, an error listing them is
+	// returned.
+	EnableAbbreviations bool
+
+	// ExpandEnvInFlags is whether to expand environment variable references
+	// (e.g. '$HOME' or '${HOME}') in the values of flags, including those
+	// read from their environment variables (see Flag.EnvVar), before they
+	// are validated.
+	ExpandEnvInFlags bool
+
+	// WriteUsageToStderr is whether to print the usage details to stderr
+	// instead of stdout when printing help, as many POSIX tools do.
+	//
+	// Errors are not printed by Run, and Execute always prints them to
+	// stderr.
+	WriteUsageToStderr bool
+
+	// HelpFormatter formats the usage details when printing help.
+	//
+	// If it is nil, the usage details are formatted as by Command.Usage,
+	// sorted as configured in the app.
+	HelpFormatter HelpFormatter
+
+	// EnablePager is whether to pipe the usage details through a pager (see
+	// kuboutil.NewPager) when printing help.
+	EnablePager bool
+
+	// EnableOutputFlag is whether every command accepts an '--output' flag
+	// for choosing the format output is encoded in (see
+	// Context.EncodeOutput), unless it defines a flag with the same name.
+	//
+	// The flag accepts the formats registered with RegisterEncoder and the
+	// default ones, and is 'json' if it is not passed.
+	EnableOutputFlag bool
+
+	// SubCommandRequired is whether running the root command with no
+	// arguments prints its usage details instead of running it, if it has
+	// child commands. This is checked before the flags are parsed, so
+	// required flags are not reported.
+	//
+	// A root command with child commands and no Run always prints its usage
+	// details when run with no arguments, as does any other command without
+	// a Run (e.g. one only grouping child commands).
+	SubCommandRequired bool
+
+	// Version is the version of the app (e.g. '1.2.3').
+	//
+	// If it is set, passing '--version' to the root command or running
+	// 'version' as its child command prints the version instead, unless the
+	// root command defines a flag or child command named 'version' itself.
+	// Both are listed in the usage details and offered by shell completion.
+	// The version command is left out if the root command has arguments, so
+	// that 'version' is parsed as an argument.
+	Version string
+
+	// VersionTemplate is the format string used to print the version, which
+	// is passed the name of the root command and the version.
+	//
+	// If it is empty, '%s version %s\n' is used.
+	VersionTemplate string
+
+	// Used for encoding output.
+	encoders map[string]EncoderFunc
+
+	// Used for printing help topics.
+	helpTopics map[string]string
+
+	// Used as the parent of the context passed to the lifecycle hooks and
+	// commands.
+	ctx context.Context
+}
+
+// Lifecycle represents the startup and shutdown hooks of an app.
+type Lifecycle struct {
+	// OnStart hooks are run in order before the command is run.
+	//
+	// If any of them returns an error, the command is not run, the OnStop
+	// hooks of those that already started are run in reverse order (the
+	// OnStop hook at the same index as each of them) and the error is
+	// returned.
+	OnStart []func(ctx context.Context) error
+
+	// OnStop hooks are run in reverse order after the command is run, even
+	// if it returns an error.
+	//
+	// All of them are run, and the error returned by the command (or
+	// otherwise the first error returned by them) is returned.
+	OnStop []func(ctx context.Context) error
 }
 
 // NewApp creates a new app with the given root command.
+//
+// It panics if any of the flags of the root command is defined inconsistently
+// (see Command.AddCommand).
 func NewApp(root *Command) *App {
-	return &App{
+	if err := root.validateFlags(); err != nil {
+		panic(err)
+	}
+
+	a := &App{
 		Root:   root,
 		Stdin:  os.Stdin,
 		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+
+		SortCommands:     true,
+		SortFlags:        true,
+		FlagInterspersed: true,
 	}
+	root.app = a
+	return a
 }
 
 // Run runs the app with the given arguments.
+//
+// If the COMP_LINE and COMP_POINT environment variables are set (e.g. by bash
+// with 'complete -C tool tool'), the completion candidates for the command
+// line are printed instead, one per line.
 func (a *App) Run(args []string) error {
-	cmd := a.Root
-	for {
-		tmpArgs := args[1:]
-
-		// Verify that multiple is only used once in the arguments
-		for i, arg := range cmd.Arguments {
-			if arg.Multiple && i != len(cmd.Arguments)-1 {
-				panic(fmt.Errorf("command %s: multiple can only be used in last argument", cmd.Name))
-			}
-		}
+	_, err := a.execute(args)
+	return err
+}
+
+// execute runs the app with the given arguments, returning the command that
+// was found along with any error.
+func (a *App) execute(args []string) (*Command, error) {
+	// Capture the parent context before anything else, so that changing it
+	// while running has no effect
+	parentCtx := a.ctx
+	if parentCtx == nil {
+		parentCtx = context.Background()
+	}
+
+	// Print the completion candidates instead if called by bash for them
+	compLine, lineOK := os.LookupEnv("COMP_LINE")
+	compPoint, pointOK := os.LookupEnv("COMP_POINT")
+	if lineOK && pointOK {
+		return a.Root, a.complete(compLine, compPoint)
+	}
+
+	if a.PreParsing != nil && len(args) > 0 {
+		args = append([]string{args[0]}, a.PreParsing(args[1:])...)
+	}
 
-		// Create the context to pass to the command
-		ctx := Context{
-			arguments: make(map[string]string),
-			flags:     make(map[string]string),
-			stdin:     a.Stdin,
-			stdout:    a.Stdout,
+	// Print the usage details of the root command instead if a child
+	// command is required but no arguments were given
+	if len(args) == 1 && len(a.Root.children) > 0 && (a.SubCommandRequired || a.Root.Run == nil) {
+		ctx := &Context{stdin: a.Stdin, stdout: a.Stdout, stderr: a.Stderr, app: a}
+		if a.Root.out != nil {
+			ctx.stdout = a.Root.out
 		}
+		if a.Root.err != nil {
+			ctx.stderr = a.Root.err
+		}
+		return a.Root, a.writeUsage(ctx, a.Root)
+	}
 
-		// flagErr is used to hold the flag not found error, which can
-		// only be returned if no subcommand is found
-		var flagErr error
-
-		// Parse all the flags in the arguments
-		for i := 0; i < len(tmpArgs); i++ {
-			arg := tmpArgs[i]
-
-			name, ok := parseFlagName(arg)
-			if ok {
-				// Try to find the flag definition
-				flag, err := cmd.flag(name)
-				if err != nil {
-					// Since it is not found, hold the flag
-					// not found error for later and simply
-					// let it parse as per normal
-					flagErr = err
-					flag.Bool = true
-				}
-
-				var value string
-				if flag.Bool {
-					value = fmt.Sprint(true)
-					tmpArgs = append(append([]string{}, tmpArgs[:i]...), tmpArgs[i+1:]...)
-				} else if i+1 < len(tmpArgs) {
-					value = tmpArgs[i+1]
-					tmpArgs = append(append([]string{}, tmpArgs[:i]...), tmpArgs[i+2:]...)
-				} else {
-					return fmt.Errorf("no value found for flag: %s", name)
-				}
-				i-- // decrement to keep index correct
-
-				// Don't set the flag in the context since it
-				// was not defined in the command
-				if flagErr == nil {
-					ctx.flags[flag.Name] = value
-				}
-			}
+	cmd, ctx, err := a.parse(args)
+	if err, ok := err.(*commandNotFoundError); ok {
+		// Print the help topic instead if its name was passed with the
+		// default help flag
+		if _, ok := a.helpTopics[err.name]; ok && ctx.help {
+			return cmd, a.writeHelpTopic(ctx, err.name)
 		}
 
-		// Set all flags with Bool to false if not set to true
-		for _, flag := range cmd.Flags {
-			if !flag.Bool {
-				continue
-			}
+		if a.OnCommandNotFound != nil {
+			a.OnCommandNotFound(ctx, err.name)
+		}
+		for _, observer := range a.CommandNotFoundObservers {
+			observer(ctx, err.name)
+		}
 
-			// Since flag is a bool flag and it is not set to true,
-			// set it to false
-			if _, err := ctx.Flag(flag.Name); err != nil {
-				ctx.flags[flag.Name] = "false"
-			}
+		if a.CommandNotFoundFunc != nil {
+			return cmd, a.CommandNotFoundFunc(ctx, err.name)
 		}
+	}
+	if err != nil {
+		return cmd, &usageError{err: err}
+	}
 
-		// Parse raw arguments as child command
-		if len(tmpArgs) > 0 {
-			// Try to find child command
-			child, err := cmd.command(tmpArgs[0])
-			if err != nil {
-				// If no child command is found and it not possibly
-				// an argument, then return the command not found
-				// error
-				if len(cmd.Arguments) == 0 {
-					return err
-				}
-			} else {
-				// Set the command to the child and pop the first
-				// argument that matches the child command name
-				cmd = child
-				for i, arg := range args {
-					if arg == tmpArgs[0] {
-						args = append(append([]string{}, args[:i]...), args[i+1:]...)
-						break
-					}
-				}
-				continue
-			}
+	for _, warning := range ctx.warnings {
+		fmt.Fprintf(ctx.Stderr(), "warning: %s\n", warning)
+	}
+
+	// Print the command tree as a DOT graph instead of running the command
+	// if the hidden default flag was passed
+	if ctx.dumpDot {
+		return cmd, a.WriteDotGraph(ctx.Stdout())
+	}
+
+	// Print the version instead of running the command if the default
+	// version flag was passed
+	if ctx.version {
+		return cmd, a.writeVersion(ctx)
+	}
+
+	// Print the usage details instead of running the command if the
+	// default help flag was passed or if there is nothing to run
+	if ctx.help || cmd.Run == nil {
+		return cmd, a.writeUsage(ctx, cmd)
+	}
+
+	if len(ctx.args) > 0 {
+		if err := cmd.TrailingArgValidator(ctx, ctx.args); err != nil {
+			return cmd, &usageError{err: err}
 		}
+	}
+
+	// Run the command
+	return cmd, a.run(parentCtx, cmd, ctx)
+}
+
+// RunWithArgs runs the app with the given arguments, which unlike Run should
+// not include the program name (e.g. os.Args[1:]).
+//
+// This is mainly useful for testing, since the arguments can be written as
+// they are typed after the program name.
+func (a *App) RunWithArgs(args []string) error {
+	return a.Run(append([]string{a.Root.Name}, args...))
+}
+
+// UseContext sets the context used as the parent of the context passed to the
+// lifecycle hooks and returned by Context.GoContext (e.g. to propagate a
+// deadline or cancellation from a server the app is embedded in).
+//
+// It only affects the subsequent calls to Run.
+func (a *App) UseContext(ctx context.Context) {
+	a.ctx = ctx
+}
+
+// Execute runs the app with os.Args, printing any error returned to Stderr,
+// and returns the exit code along with the error.
+//
+// The exit code is 0 on success, 2 if the arguments could not be parsed or
+// validated, the code of an ExitError if one is returned and 1 for other
+// errors. This allows main to simply call os.Exit with it.
+//
+//	code, _ := app.Execute()
+//	os.Exit(code)
+func (a *App) Execute() (int, error) {
+	err := a.Run(os.Args)
+	if err == nil {
+		return 0, nil
+	}
+	stderr := a.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	fmt.Fprintf(stderr, "error: %s\n", err)
+
+	var exitErr *kuboutil.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code, err
+	}
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		return 2, err
+	}
+	return 1, err
+}
+
+// RunWithExitCode runs the app with os.Args like Execute, printing any error
+// returned to Stderr, and returns only the exit code.
+//
+//	os.Exit(app.RunWithExitCode())
+func (a *App) RunWithExitCode() int {
+	code, _ := a.Execute()
+	return code
+}
 
-		// Since no subcommand is found, the flag not found error should
-		// be returned if it is not nil
-		if flagErr != nil {
-			return flagErr
+// ExitError is an error with the exit code the app should exit with, which
+// can be returned from Run (see kuboutil.ExitError).
+type ExitError = kuboutil.ExitError
+
+// ExecuteC runs the app with os.Args like Execute, but captures what is written
+// to stdout instead, returning it along with the command that was found and
+// any error returned.
+//
+// If a child command is not found, the command it was looked up in is
+// returned, and if the arguments could not be parsed otherwise, the command is
+// nil. The app itself is not modified.
+func (a *App) ExecuteC() (*Command, string, error) {
+	var stdout strings.Builder
+
+	app := *a
+	app.Stdout = &stdout
+
+	cmd, err := app.execute(os.Args)
+	return cmd, stdout.String(), err
+}
+
+// usageError is returned when the arguments could not be parsed or validated.
+type usageError struct {
+	err error
+}
+
+func (err *usageError) Error() string {
+	return err.err.Error()
+}
+
+func (err *usageError) Unwrap() error {
+	return err.err
+}
+
+// helpFormatter returns the help formatter of the app, or the default one as
+// configured in the app if there is none.
+func (a *App) helpFormatter() HelpFormatter {
+	if a.HelpFormatter != nil {
+		return a.HelpFormatter
+	}
+	return defaultHelpFormatter{
+		app:             a,
+		sortCommands:    a.SortCommands,
+		sortFlags:       a.SortFlags,
+		hideGlobalFlags: a.HideGlobalFlags,
+	}
+}
+
+// HelpTopics returns the names of the help topics added to the app, sorted by
+// name.
+func (a *App) HelpTopics() []string {
+	var topics []string
+	for topic := range a.helpTopics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// AddHelpTopic adds a help topic with the given name and body, which is
+// printed by the help command (e.g. 'help config') or when the name is passed
+// with the default help flag (e.g. 'config --help').
+//
+// The names of the topics are listed in the usage details of the root command.
+func (a *App) AddHelpTopic(name, body string) {
+	if a.helpTopics == nil {
+		a.helpTopics = make(map[string]string)
+	}
+	a.helpTopics[name] = body
+}
+
+// DefaultFlags returns the default flags of the app that the given command
+// accepts (e.g. '--version' and '--output'), leaving out those it defines
+// itself, or none if the app is nil.
+//
+// The default help flag is not included, since it is accepted by every command
+// and never listed.
+func (a *App) DefaultFlags(cmd *Command) []Flag {
+	if a == nil {
+		return nil
+	}
+
+	var flags []Flag
+	if cmd == a.Root && a.Version != "" {
+		flags = append(flags, Flag{
+			Name:        "version",
+			Bool:        true,
+			Description: "prints the version",
+		})
+	}
+	if a.EnableOutputFlag {
+		flags = append(flags, Flag{
+			Name:          "output",
+			Type:          "format",
+			Description:   "format of the output",
+			Default:       "json",
+			AllowedValues: formats(a.encoders),
+		})
+	}
+
+	var defaultFlags []Flag
+	for _, flag := range flags {
+		if _, err := cmd.flag(flag.Name); err != nil {
+			defaultFlags = append(defaultFlags, flag)
 		}
+	}
+	return defaultFlags
+}
 
-		// Parse raw arguments as arguments
-		for _, arg := range cmd.Arguments {
-			if len(tmpArgs) == 0 {
-				return fmt.Errorf("argument not found: %s", arg.Name)
-			}
+// flag returns the flag of the given command with the given name or alias,
+// falling back to the default flags of the app.
+func (a *App) flag(cmd *Command, nameOrAlias string) (Flag, error) {
+	flag, err := cmd.flag(nameOrAlias)
+	if err == nil {
+		return flag, nil
+	}
+	for _, defaultFlag := range a.DefaultFlags(cmd) {
+		if defaultFlag.Name == nameOrAlias {
+			return defaultFlag, nil
+		}
+	}
+	return Flag{}, err
+}
 
-			if arg.Multiple {
-				ctx.argumentMultipleName = arg.Name
-				ctx.argumentMultipleValue = tmpArgs
-				tmpArgs = nil
-				break
-			}
+// DefaultCommands returns the default child commands of the app that the
+// given command accepts (e.g. 'version'), leaving out those it defines itself,
+// or none if the app is nil.
+//
+// The default version command is only accepted by a root command without
+// arguments, so that 'version' can still be passed as an argument otherwise.
+func (a *App) DefaultCommands(cmd *Command) []*Command {
+	if a == nil || cmd != a.Root || a.Version == "" || len(cmd.arguments()) > 0 {
+		return nil
+	}
+	if _, err := cmd.command("version", false, false); err == nil {
+		return nil
+	}
+	return []*Command{a.versionCommand()}
+}
 
-			ctx.arguments[arg.Name] = tmpArgs[0]
-			tmpArgs = tmpArgs[1:]
+// command returns the child command of the given command with the given name
+// or alias, falling back to the default child commands of the app.
+func (a *App) command(cmd *Command, nameOrAlias string) (*Command, error) {
+	child, err := cmd.command(nameOrAlias, a.EnableCaseInsensitiveCommands, a.EnableAbbreviations)
+	if _, ok := err.(*commandNotFoundError); !ok {
+		return child, err
+	}
+	for _, defaultCmd := range a.DefaultCommands(cmd) {
+		if defaultCmd.Name == nameOrAlias {
+			return defaultCmd, nil
 		}
-		if len(tmpArgs) > 0 {
-			return fmt.Errorf("extra arguments supplied")
+	}
+	return nil, err
+}
+
+// versionCommand returns the default version command, which is run as a child
+// of the root command.
+func (a *App) versionCommand() *Command {
+	return &Command{
+		Name:        "version",
+		Description: "prints the version",
+		Run: func(ctx *Context) error {
+			return a.writeVersion(ctx)
+		},
+		parent: a.Root,
+	}
+}
+
+// writeVersion writes the version of the app to the stdout of the given
+// context, formatted with VersionTemplate.
+func (a *App) writeVersion(ctx *Context) error {
+	template := a.VersionTemplate
+	if template == "" {
+		template = "%s version %s\n"
+	}
+	_, err := fmt.Fprintf(ctx.Stdout(), template, a.Root.Name, a.Version)
+	return err
+}
+
+// writeHelpTopic writes the body of the given help topic to the stdout (or
+// stderr if enabled) of the given context.
+func (a *App) writeHelpTopic(ctx *Context, topic string) error {
+	w := ctx.Stdout()
+	if a.WriteUsageToStderr {
+		w = ctx.Stderr()
+	}
+	_, err := fmt.Fprintln(w, strings.TrimRight(a.helpTopics[topic], "\n"))
+	return err
+}
+
+// writeUsage writes the usage details of the given command using the help
+// formatter to the stdout (or stderr if enabled) of the given context, through
+// a pager if enabled.
+func (a *App) writeUsage(ctx *Context, cmd *Command) error {
+	w := ctx.Stdout()
+	if a.WriteUsageToStderr {
+		w = ctx.Stderr()
+	}
+
+	if !a.EnablePager {
+		return a.helpFormatter().Format(cmd, w)
+	}
+
+	pager := kuboutil.NewPager(w)
+	if err := a.helpFormatter().Format(cmd, pager); err != nil {
+		pager.Close()
+		return err
+	}
+	return pager.Close()
+}
+
+// run runs the given command with the given context within the lifecycle of
+// the app, deriving the context passed to the lifecycle hooks from the given
+// parent.
+func (a *App) run(parentCtx context.Context, cmd *Command, ctx *Context) error {
+	lifecycleCtx, cancel := context.WithCancel(parentCtx)
+	defer cancel()
+	ctx.goCtx = &goContext{parent: lifecycleCtx}
+
+	for i, onStart := range a.Lifecycle.OnStart {
+		if err := onStart(lifecycleCtx); err != nil {
+			// Stop the hooks that already started
+			for j := minInt(i, len(a.Lifecycle.OnStop)) - 1; j >= 0; j-- {
+				a.Lifecycle.OnStop[j](lifecycleCtx)
+			}
+			return err
 		}
+	}
 
-		// Run the command
-		return cmd.Run(&ctx)
+	err := cmd.run(ctx)
+
+	for i := len(a.Lifecycle.OnStop) - 1; i >= 0; i-- {
+		if stopErr := a.Lifecycle.OnStop[i](lifecycleCtx); stopErr != nil && err == nil {
+			err = stopErr
+		}
 	}
+
+	return err
 }
 
-// parseFlagName parses the given argument for a flag name, returning the name
-// and a flag whether it was found.
-func parseFlagName(arg string) (string, bool) {
-	matches := longFlagRegexp.FindStringSubmatch(arg)
-	if len(matches) > 1 {
-		return matches[1], true
+// RegisterEncoder registers an encoder for the given format, which can then be
+// used to encode output from the context.
+//
+// The 'json' format is registered by default, but can be replaced.
+func (a *App) RegisterEncoder(format string, enc EncoderFunc) {
+	if a.encoders == nil {
+		a.encoders = make(map[string]EncoderFunc)
 	}
+	a.encoders[format] = enc
+}
 
-	matches = shortFlagRegexp.FindStringSubmatch(arg)
-	if len(matches) > 1 {
-		return matches[1], true
+// RunWithInput runs the app with the given arguments, reading from the given
+// stdin, and returns what was written to stdout and stderr.
+//
+// This is mainly useful for testing. The app itself is not modified.
+func (a *App) RunWithInput(args []string, stdin io.Reader) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+
+	app := *a
+	app.Stdin = stdin
+	app.Stdout = &stdout
+	app.Stderr = &stderr
+
+	err := app.Run(args)
+	return stdout.String(), stderr.String(), err
+}
+
+// WriteDotGraph writes a Graphviz DOT representation of the command tree to
+// the given writer.
+//
+// Each command is a node labelled with its name and description (dashed if it
+// is hidden), with edges going from each command to its children. The output
+// can be passed to 'dot -Tsvg' to render the diagram.
+//
+// The graph is also printed when the hidden '--dump-dot' flag is passed to the
+// root command, unless it defines a flag with the same name.
+func (a *App) WriteDotGraph(w io.Writer) error {
+	var graph strings.Builder
+	graph.WriteString("digraph {\n")
+	writeDotNode(&graph, a.Root)
+	graph.WriteString("}\n")
+
+	_, err := io.WriteString(w, graph.String())
+	return err
+}
+
+// writeDotNode writes the node for the given command and the edges to its
+// children, recursing into each child.
+func writeDotNode(graph *strings.Builder, cmd *Command) {
+	label := cmd.Name
+	if description := cmd.ShortDescription(); description != "" {
+		label = fmt.Sprintf("%s\n%s", label, description)
+	}
+	if cmd.Hidden {
+		graph.WriteString(fmt.Sprintf("\t%q [label=%q, style=dashed];\n", cmd.FullName(), label))
+	} else {
+		graph.WriteString(fmt.Sprintf("\t%q [label=%q];\n", cmd.FullName(), label))
 	}
 
-	return "", false
+	for _, child := range cmd.children {
+		graph.WriteString(fmt.Sprintf("\t%q -> %q;\n", cmd.FullName(), child.FullName()))
+		writeDotNode(graph, child)
+	}
 }
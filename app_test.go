@@ -0,0 +1,308 @@
+package kubo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ravernkoh/kubo"
+	"github.com/ravernkoh/kubo/kubotest"
+)
+
+func TestRunWithInputConcurrently(t *testing.T) {
+	root := &kubo.Command{
+		Name:      "root",
+		Arguments: []kubo.Argument{{Name: "name"}},
+		Run: func(ctx *kubo.Context) error {
+			name, err := ctx.Argument("name")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(ctx.Stdout(), "hello, %s\n", name)
+			return nil
+		},
+	}
+	app := kubo.NewApp(root)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			name := fmt.Sprint(i)
+			stdout, _, err := app.RunWithInput([]string{"root", name}, strings.NewReader(""))
+			if err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+			if expected := fmt.Sprintf("hello, %s\n", name); stdout != expected {
+				t.Errorf("expected stdout %q, got %q", expected, stdout)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestRunExpandEnvInFlags(t *testing.T) {
+	t.Setenv("KUBO_TEST_DIR", "/tmp")
+	t.Setenv("KUBO_TEST_PATH", "$KUBO_TEST_DIR/out")
+
+	root := &kubo.Command{
+		Name: "root",
+		Flags: []kubo.Flag{
+			{Name: "path", EnvVar: "KUBO_TEST_PATH"},
+		},
+		Run: func(ctx *kubo.Context) error {
+			path, err := ctx.Flag("path")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(ctx.Stdout(), path)
+			return nil
+		},
+	}
+	app := kubo.NewApp(root)
+
+	kubotest.New(app).Run().AssertStdout(t, "$KUBO_TEST_DIR/out\n")
+
+	app.ExpandEnvInFlags = true
+	kubotest.New(app).Run().AssertStdout(t, "/tmp/out\n")
+	kubotest.New(app).Run("--path", "${KUBO_TEST_DIR}/in").AssertStdout(t, "/tmp/in\n")
+}
+
+func TestRunLifecycleStartError(t *testing.T) {
+	var calls []string
+	hook := func(name string, err error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	root := &kubo.Command{
+		Name: "root",
+		Run: func(ctx *kubo.Context) error {
+			calls = append(calls, "run")
+			return nil
+		},
+	}
+	app := kubo.NewApp(root)
+	app.Lifecycle.OnStart = []func(ctx context.Context) error{
+		hook("start db", nil),
+		hook("start server", nil),
+		hook("start queue", errors.New("queue unavailable")),
+	}
+	app.Lifecycle.OnStop = []func(ctx context.Context) error{
+		hook("stop db", nil),
+		hook("stop server", nil),
+		hook("stop queue", nil),
+	}
+
+	res := kubotest.New(app).Run()
+	if res.Err == nil || res.Err.Error() != "queue unavailable" {
+		t.Fatalf("expected the start error, got: %v", res.Err)
+	}
+	expected := []string{"start db", "start server", "start queue", "stop server", "stop db"}
+	if strings.Join(calls, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("expected calls %q, got %q", expected, calls)
+	}
+}
+
+func TestRunSubCommandRequired(t *testing.T) {
+	root := &kubo.Command{
+		Name:      "root",
+		Arguments: []kubo.Argument{{Name: "args", Optional: true, Multiple: true}},
+		Flags: []kubo.Flag{
+			{Name: "token", Description: "the token", Required: true},
+		},
+		Run: func(ctx *kubo.Context) error {
+			fmt.Fprintln(ctx.Stdout(), "root")
+			return nil
+		},
+	}
+	app := kubo.NewApp(root)
+	child := &kubo.Command{
+		Name: "child",
+		Run: func(ctx *kubo.Context) error {
+			fmt.Fprintln(ctx.Stdout(), "child")
+			return nil
+		},
+	}
+	root.Add(child)
+
+	kubotest.New(app).Run("--token", "x").AssertStdout(t, "root\n")
+
+	app.SubCommandRequired = true
+	res := kubotest.New(app).Run()
+	res.AssertExitOK(t)
+	if !strings.HasPrefix(res.Stdout, "name\n\troot") {
+		t.Errorf("expected the usage details, got %q", res.Stdout)
+	}
+	kubotest.New(app).Run("--token", "x", "a").AssertStdout(t, "root\n")
+	kubotest.New(app).Run("child").AssertStdout(t, "child\n")
+}
+
+func TestRunWithoutRun(t *testing.T) {
+	root := &kubo.Command{Name: "root"}
+	app := kubo.NewApp(root)
+	group := &kubo.Command{Name: "group"}
+	group.Add(&kubo.Command{Name: "child", Run: func(ctx *kubo.Context) error { return nil }})
+	root.Add(group)
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "\tgroup"},
+		{[]string{"group"}, "\tchild"},
+	}
+
+	for _, test := range tests {
+		res := kubotest.New(app).Run(test.args...)
+		res.AssertExitOK(t)
+		if !strings.Contains(res.Stdout, test.expected) {
+			t.Errorf("%v: expected the usage details listing %q, got %q", test.args, test.expected, res.Stdout)
+		}
+	}
+}
+
+func TestRunVersion(t *testing.T) {
+	root := &kubo.Command{Name: "root"}
+	app := kubo.NewApp(root)
+	app.Version = "1.2.3"
+
+	kubotest.New(app).Run("version").AssertStdout(t, "root version 1.2.3\n")
+	kubotest.New(app).Run("--version").AssertStdout(t, "root version 1.2.3\n")
+}
+
+func TestRunVersionWithArguments(t *testing.T) {
+	root := &kubo.Command{
+		Name:      "root",
+		Arguments: []kubo.Argument{{Name: "name"}},
+		Run: func(ctx *kubo.Context) error {
+			name, err := ctx.Argument("name")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(ctx.Stdout(), "hello, %s\n", name)
+			return nil
+		},
+	}
+	app := kubo.NewApp(root)
+	app.Version = "1.2.3"
+
+	kubotest.New(app).Run("version").AssertStdout(t, "hello, version\n")
+	kubotest.New(app).Run("--version").AssertStdout(t, "root version 1.2.3\n")
+}
+
+func TestRunDumpDot(t *testing.T) {
+	root := &kubo.Command{Name: "root", Run: func(*kubo.Context) error { return nil }}
+	root.Add(&kubo.Command{Name: "child", Description: "a child"})
+	root.Add(&kubo.Command{Name: "secret", Hidden: true})
+
+	kubotest.New(kubo.NewApp(root)).Run("--dump-dot").AssertStdout(t, strings.Join([]string{
+		"digraph {",
+		"\t\"root\" [label=\"root\"];",
+		"\t\"root\" -> \"root child\";",
+		"\t\"root child\" [label=\"child\\na child\"];",
+		"\t\"root\" -> \"root secret\";",
+		"\t\"root secret\" [label=\"secret\", style=dashed];",
+		"}",
+	}, "\n")+"\n")
+}
+
+func TestRunOutputFlag(t *testing.T) {
+	run := func(ctx *kubo.Context) error {
+		return ctx.EncodeOutput(map[string]int{"a": 1})
+	}
+	root := &kubo.Command{Name: "root", Run: run}
+	root.Add(&kubo.Command{Name: "child", Run: run})
+	app := kubo.NewApp(root)
+	app.EnableOutputFlag = true
+	app.RegisterEncoder("text", func(v interface{}, w io.Writer) error {
+		_, err := fmt.Fprintln(w, v)
+		return err
+	})
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{}, "{\n  \"a\": 1\n}\n"},
+		{[]string{"--output", "json"}, "{\n  \"a\": 1\n}\n"},
+		{[]string{"--output", "text"}, "map[a:1]\n"},
+		{[]string{"child", "--output", "text"}, "map[a:1]\n"},
+		{[]string{"--output", "text", "child"}, "map[a:1]\n"},
+	}
+
+	for _, test := range tests {
+		kubotest.New(app).Run(test.args...).AssertStdout(t, test.expected)
+	}
+
+	if res := kubotest.New(app).Run("--output", "nope"); res.Err == nil {
+		t.Fatal("expected an error, got none")
+	}
+}
+
+func TestRunCleanupOnError(t *testing.T) {
+	tests := []struct {
+		cleanupOnError bool
+		beforeErr      error
+		panics         bool
+		expected       []string
+	}{
+		{false, nil, false, []string{"root before", "child before", "run", "child after", "root after"}},
+		{false, errors.New("failed"), false, []string{"root before", "child before"}},
+		{true, errors.New("failed"), false, []string{"root before", "child before", "child after", "root after"}},
+		{true, nil, true, []string{"root before", "child before", "run", "child after", "root after"}},
+	}
+
+	for i, test := range tests {
+		var calls []string
+		hook := func(name string, err error) func(ctx *kubo.Context) error {
+			return func(ctx *kubo.Context) error {
+				calls = append(calls, name)
+				return err
+			}
+		}
+
+		root := &kubo.Command{
+			Name:   "root",
+			Before: hook("root before", nil),
+			After:  hook("root after", nil),
+		}
+		root.Add(&kubo.Command{
+			Name:   "child",
+			Before: hook("child before", test.beforeErr),
+			After:  hook("child after", nil),
+			Run: func(ctx *kubo.Context) error {
+				calls = append(calls, "run")
+				if test.panics {
+					panic("run panicked")
+				}
+				return nil
+			},
+		})
+		app := kubo.NewApp(root)
+		app.CleanupOnError = test.cleanupOnError
+
+		func() {
+			defer func() {
+				if r := recover(); (r != nil) != test.panics {
+					t.Errorf("test %d: expected panic %v, got: %v", i, test.panics, r)
+				}
+			}()
+			res := kubotest.New(app).Run("child")
+			if res.Err != test.beforeErr {
+				t.Errorf("test %d: expected error %v, got: %v", i, test.beforeErr, res.Err)
+			}
+		}()
+		if strings.Join(calls, ", ") != strings.Join(test.expected, ", ") {
+			t.Errorf("test %d: expected calls %q, got %q", i, test.expected, calls)
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package kubo
 
+import "fmt"
+
 // Argument represents an argument for a command.
 type Argument struct {
+	// Name is the name of the argument, which can't contain whitespace (use
+	// hyphens instead, e.g. 'input-file').
 	Name string
 
 	// Multiple is whether this argument collects multiple arguments.
@@ -10,4 +14,43 @@ type Argument struct {
 	// 'command <argument1> <argument2> <arguments...>'). It should only
 	// be used at the end of the argument list.
 	Multiple bool
+
+	// Optional is whether this argument can be omitted.
+	//
+	// Only arguments at the end of the argument list (followed by other
+	// optional arguments only) can be optional, so a multiple argument after
+	// an optional one must also be optional.
+	Optional bool
+
+	// Default is the value of an optional argument if it is omitted, which
+	// is empty if it is not set. An omitted multiple argument collects no
+	// values, or only this one if it is set.
+	Default string
+
+	// AllowedValues are the values the argument can have, which are offered
+	// by shell completion.
+	//
+	// A value can be followed by a tab and a description (e.g. 'json\tJSON
+	// output'), which is shown alongside it when completing.
+	AllowedValues []string
+
+	// Validator validates each value of the argument when it is passed (e.g.
+	// kuboutil.IsInt), but not the default value of an omitted argument.
+	//
+	// Any error returned is returned as a parse error, before the command is
+	// run.
+	Validator func(string) error
+}
+
+// Usage returns the argument as shown in the command usage (e.g. '<argument>'
+// or '[<arguments>...]').
+func (arg *Argument) Usage() string {
+	usage := fmt.Sprintf("<%s>", arg.Name)
+	if arg.Multiple {
+		usage = fmt.Sprintf("%s...", usage)
+	}
+	if arg.Optional {
+		usage = fmt.Sprintf("[%s]", usage)
+	}
+	return usage
 }
@@ -2,7 +2,12 @@ package kubo
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
+
+	"github.com/ravernkoh/kubo/kuboutil"
 )
 
 // Command represents a command or a subcommand.
@@ -11,9 +16,54 @@ type Command struct {
 	Aliases     []string
 	Description string
 
+	// Short is the one-line description shown when the command is listed
+	// under its parent.
+	//
+	// If it is empty, Description is used instead. Likewise, Short is used
+	// for the command's own help page if Description is empty.
+	Short string
+
+	// LongDescription is the detailed description shown in the command's own
+	// help page, after Description.
+	//
+	// It can span multiple lines, which are wrapped to the width of the
+	// terminal. Lines starting with whitespace are kept as is.
+	LongDescription string
+
+	// Deprecated is the reason the command is deprecated (e.g. 'use new
+	// instead').
+	//
+	// If it is set, the command is marked as deprecated in the usage details
+	// and a warning is printed to stderr when it is run.
+	Deprecated string
+
+	// Hidden is whether the command is left out when listing the child
+	// commands in the usage details of its parent and in shell completion.
+	//
+	// It can still be run (and its own usage details printed) as usual.
+	Hidden bool
+
+	// Group is the name of the group the command is listed under in the
+	// usage details of its parent (e.g. 'repository').
+	//
+	// The groups are listed in the order they are first seen, followed by
+	// the commands without a group under 'other commands'. If all the child
+	// commands are in the same group, they are listed under 'commands'.
+	Group string
+
 	Arguments []Argument // should be in order
 	Flags     []Flag
 
+	// PersistentFlags are flags that are inherited by all descendants of the
+	// command, as if they were defined with Persistent set.
+	PersistentFlags []Flag
+
+	// PositionalArgs is an alias for Arguments.
+	//
+	// Only one of them should be set, otherwise an error is returned when the
+	// command is parsed.
+	PositionalArgs []Argument
+
 	// Run runs the command.
 	//
 	// Any error returned is propogated and returned to the main Run function
@@ -23,19 +73,185 @@ type Command struct {
 	// the context is used.
 	Run func(*Context) error
 
+	// Before is run before Run, after the Before of the parent command (if
+	// any), so that the hooks are run in order from the root command.
+	//
+	// If any of them returns an error, the error is returned without running
+	// Run or any After (unless App.CleanupOnError is set).
+	Before func(*Context) error
+
+	// After is run after Run, before the After of the parent command (if
+	// any), even if Run returns an error.
+	//
+	// The error returned by Run (or otherwise the first error returned by the
+	// hooks) is returned.
+	After func(*Context) error
+
+	// TrailingArgValidator validates the extra arguments supplied after all
+	// the arguments have been parsed.
+	//
+	// If it is nil, supplying extra arguments returns an error. Otherwise,
+	// the extra arguments are passed to it (and are also available from
+	// the context) and any error returned is propagated. To accept any
+	// number of extra arguments, simply return nil.
+	TrailingArgValidator func(ctx *Context, trailingArgs []string) error
+
 	// Used for generating help command.
 	parent   *Command
 	children []*Command
+
+	// Used for looking up flags.
+	normalizeFlag func(string) string
+
+	// Used for overriding the writers of the app.
+	out io.Writer
+	err io.Writer
+
+	// Used for falling back to the app created with the command tree, which
+	// is only set on the root command by NewApp. The app running a command
+	// is passed in its context instead, since it may be a copy (e.g. in
+	// RunWithInput).
+	app *App
 }
 
-// Add adds a child command.
+// Add adds a child command, panicking if it can't be added (see AddCommand).
 func (cmd *Command) Add(child *Command) {
+	if err := cmd.AddCommand(child); err != nil {
+		panic(err)
+	}
+}
+
+// AddCommand adds a child command and returns an error if it can't be added.
+//
+// A child can't be added if its name is empty or invalid (e.g. contains
+// whitespace or starts with a dash), if its name or any alias is already
+// used by another child, if it has already been added to a command or if any
+// of its arguments or flags is defined incorrectly.
+func (cmd *Command) AddCommand(child *Command) error {
+	if child == nil {
+		return fmt.Errorf("command %s: child command is nil", cmd.Name)
+	}
+	if child == cmd {
+		return fmt.Errorf("command %s: can't be added to itself", cmd.Name)
+	}
+	if child.parent != nil {
+		return fmt.Errorf("command %s: already added to %s", child.Name, child.parent.FullName())
+	}
+
+	if child.Arguments != nil && child.PositionalArgs != nil {
+		return fmt.Errorf("command %s: only one of arguments and positional args can be set", child.Name)
+	}
+	if err := child.validateArguments(); err != nil {
+		return err
+	}
+	if err := child.validateFlags(); err != nil {
+		return err
+	}
+
+	names := append([]string{child.Name}, child.Aliases...)
+	for _, name := range names {
+		if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n") {
+			return fmt.Errorf("command %s: invalid name or alias: %q", cmd.Name, name)
+		}
+		if _, err := cmd.command(name, false, false); err == nil {
+			return fmt.Errorf("command %s: name or alias already used: %s", cmd.Name, name)
+		}
+	}
+
 	child.parent = cmd
 	cmd.children = append(cmd.children, child)
+	return nil
+}
+
+// Children returns the child commands that are not hidden, in the order they
+// were added.
+func (cmd *Command) Children() []*Command {
+	var children []*Command
+	for _, child := range cmd.children {
+		if !child.Hidden {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// AllChildren returns all the child commands, including hidden ones, in the
+// order they were added.
+func (cmd *Command) AllChildren() []*Command {
+	return append([]*Command{}, cmd.children...)
+}
+
+// SubCommandNames returns the names (without aliases) of the child commands
+// that are not hidden, sorted alphabetically.
+func (cmd *Command) SubCommandNames() []string {
+	var names []string
+	for _, child := range cmd.Children() {
+		names = append(names, child.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AllSubCommandNames is like SubCommandNames, but also includes hidden child
+// commands.
+func (cmd *Command) AllSubCommandNames() []string {
+	var names []string
+	for _, child := range cmd.children {
+		names = append(names, child.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateArguments returns an error if the arguments are defined incorrectly
+// (e.g. a required argument after an optional one).
+func (cmd *Command) validateArguments() error {
+	arguments := cmd.arguments()
+
+	// Verify that argument names have no whitespace, that multiple is only
+	// used once in the arguments and that optional arguments are only at
+	// the end
+	for i, arg := range arguments {
+		if strings.ContainsAny(arg.Name, " \t\n") {
+			return fmt.Errorf("command %s: argument names can't contain whitespace: %q", cmd.Name, arg.Name)
+		}
+		if arg.Multiple && i != len(arguments)-1 {
+			return fmt.Errorf("command %s: multiple can only be used in last argument", cmd.Name)
+		}
+		if i > 0 && arguments[i-1].Optional && !arg.Optional {
+			return fmt.Errorf("command %s: optional arguments can only be followed by optional arguments", cmd.Name)
+		}
+	}
+	return nil
+}
+
+// validateFlags returns an error if any of the flags is defined inconsistently
+// (e.g. both required and with a default, or with a default that is not
+// allowed).
+func (cmd *Command) validateFlags() error {
+	for _, flag := range cmd.ownFlags() {
+		if flag.Required && flag.Default != "" {
+			return fmt.Errorf("command %s: flag %s can't be both required and have a default", cmd.Name, flag.Name)
+		}
+		if flag.Default != "" {
+			if err := flag.checkAllowedValue(flag.Default); err != nil {
+				return fmt.Errorf("command %s: default of flag %s: %v", cmd.Name, flag.Name, err)
+			}
+		}
+	}
+	return nil
 }
 
 // command returns the child command with the given name or alias.
-func (cmd *Command) command(nameOrAlias string) (*Command, error) {
+//
+// If foldCase is set and there is no exact match, the name or alias is
+// compared case-insensitively. If abbreviate is set and there is still no
+// match, the child command whose name or alias starts with the given name is
+// returned, and an error if there is more than one.
+//
+// Hidden child commands can only be matched exactly, so that they are neither
+// revealed by nor make ambiguous the other matches.
+func (cmd *Command) command(nameOrAlias string, foldCase, abbreviate bool) (*Command, error) {
 	for _, child := range cmd.children {
 		if child.Name == nameOrAlias {
 			return child, nil
@@ -46,114 +262,475 @@ func (cmd *Command) command(nameOrAlias string) (*Command, error) {
 			}
 		}
 	}
-	return nil, fmt.Errorf("command not defined: %s", nameOrAlias)
+	if foldCase {
+		for _, child := range cmd.Children() {
+			if strings.EqualFold(child.Name, nameOrAlias) {
+				return child, nil
+			}
+			for _, alias := range child.Aliases {
+				if strings.EqualFold(alias, nameOrAlias) {
+					return child, nil
+				}
+			}
+		}
+	}
+	if abbreviate {
+		hasPrefix := strings.HasPrefix
+		if foldCase {
+			hasPrefix = func(s, prefix string) bool {
+				return strings.HasPrefix(strings.ToLower(s), strings.ToLower(prefix))
+			}
+		}
+
+		var matches []*Command
+		var names []string
+		for _, child := range cmd.Children() {
+			for _, name := range append([]string{child.Name}, child.Aliases...) {
+				if hasPrefix(name, nameOrAlias) {
+					matches = append(matches, child)
+					names = append(names, child.Name)
+					break
+				}
+			}
+		}
+		if len(matches) == 1 {
+			return matches[0], nil
+		}
+		if len(matches) > 1 {
+			sort.Strings(names)
+			return nil, fmt.Errorf("command %s is ambiguous: could be %s", nameOrAlias, strings.Join(names, ", "))
+		}
+	}
+	return nil, &commandNotFoundError{name: nameOrAlias}
+}
+
+// SubCommandSuggestions returns the names of the child commands that are not
+// hidden whose name or any alias is within an edit distance of 2 from the given
+// name, sorted alphabetically.
+//
+// This can be used to suggest commands when a typed command is not found.
+func (cmd *Command) SubCommandSuggestions(typed string) []string {
+	var suggestions []string
+	for _, child := range cmd.Children() {
+		for _, name := range append([]string{child.Name}, child.Aliases...) {
+			if editDistance(typed, name) <= 2 {
+				suggestions = append(suggestions, child.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(suggestions)
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between the given strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// Only keep the previous row of the distance matrix
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr := make([]int, len(rb)+1)
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(rb)]
+}
+
+// minInt returns the smallest of the given ints.
+func minInt(n int, ns ...int) int {
+	for _, m := range ns {
+		if m < n {
+			n = m
+		}
+	}
+	return n
+}
+
+// commandNotFoundError is returned when a child command is not found.
+type commandNotFoundError struct {
+	name string
+
+	// suggestions are the names of the child commands similar to the name
+	// (see Command.SubCommandSuggestions), which are given as a hint
+	suggestions []string
+}
+
+func (err *commandNotFoundError) Error() string {
+	if len(err.suggestions) > 0 {
+		return fmt.Sprintf("command not defined: %s, did you mean: %s?", err.name, strings.Join(err.suggestions, ", "))
+	}
+	return fmt.Sprintf("command not defined: %s", err.name)
+}
+
+// SetFlagNormalizationFunc sets the function used to normalize flag names when
+// looking up flags.
+//
+// It is applied to both the names and aliases of the defined flags and the
+// flag names passed, so that different forms of the same name (e.g. 'dry-run'
+// and 'dry_run') resolve to the same flag.
+func (cmd *Command) SetFlagNormalizationFunc(normalize func(name string) string) {
+	cmd.normalizeFlag = normalize
+}
+
+// SetOut sets the writer used as stdout when running the command, instead of
+// the stdout defined in the app.
+func (cmd *Command) SetOut(w io.Writer) {
+	cmd.out = w
 }
 
-// flag returns the flag with the given name or alias.
+// SetErr sets the writer used as stderr when running the command, instead of
+// the stderr defined in the app.
+func (cmd *Command) SetErr(w io.Writer) {
+	cmd.err = w
+}
+
+// OutOrStdout returns the writer set by SetOut, or otherwise the stdout of the
+// app created with the command tree (or os.Stdout if there is none).
+//
+// While the command is running, Context.Stdout should be used instead, which
+// also respects the writers of a copy of the app (e.g. in RunWithInput).
+func (cmd *Command) OutOrStdout() io.Writer {
+	if cmd.out != nil {
+		return cmd.out
+	}
+	if app := cmd.rootApp(); app != nil && app.Stdout != nil {
+		return app.Stdout
+	}
+	return os.Stdout
+}
+
+// ErrOrStderr returns the writer set by SetErr, or otherwise the stderr of the
+// app created with the command tree (or os.Stderr if there is none).
+//
+// While the command is running, Context.Stderr should be used instead.
+func (cmd *Command) ErrOrStderr() io.Writer {
+	if cmd.err != nil {
+		return cmd.err
+	}
+	if app := cmd.rootApp(); app != nil && app.Stderr != nil {
+		return app.Stderr
+	}
+	return os.Stderr
+}
+
+// rootApp returns the app created with the command tree, or nil if there is
+// none.
+func (cmd *Command) rootApp() *App {
+	root := cmd
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root.app
+}
+
+// FlagNames returns the names (without aliases) of the flags of the command,
+// including its persistent flags, sorted alphabetically.
+func (cmd *Command) FlagNames() []string {
+	var names []string
+	for _, flag := range cmd.ownFlags() {
+		names = append(names, flag.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AllFlagNames is like FlagNames, but also includes the names of the flags
+// inherited from the ancestors of the command.
+func (cmd *Command) AllFlagNames() []string {
+	var names []string
+	for _, flag := range cmd.allFlags() {
+		names = append(names, flag.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SecretFlagNames returns the names and aliases (e.g. '--password' and '-p')
+// of the flags defined with Secret by the command, its ancestors and its
+// descendants, for use with kuboutil.RedactedArgs.
+func (cmd *Command) SecretFlagNames() []string {
+	var names []string
+	for _, flag := range cmd.allFlags() {
+		if flag.Secret {
+			names = append(names, flag.names()...)
+		}
+	}
+	for _, child := range cmd.children {
+		names = append(names, child.SecretFlagNames()...)
+	}
+	return kuboutil.UniqueStrings(names)
+}
+
+// MarkFlagPersistent makes the flag with the given name persistent, which is
+// the same as setting Persistent when defining it, and returns an error if the
+// flag is not defined.
+func (cmd *Command) MarkFlagPersistent(name string) error {
+	for i := range cmd.Flags {
+		if cmd.Flags[i].Name == name {
+			cmd.Flags[i].Persistent = true
+			return nil
+		}
+	}
+	return fmt.Errorf("flag not defined: %s", name)
+}
+
+// MarkPersistentFlagRequired makes the persistent flag with the given name
+// required, which is the same as setting Required when defining it. The flag
+// is looked up on the command and then its ancestors, using the closest
+// definition.
+//
+// An error is returned if no persistent flag with the name is defined, or if
+// it has a default (see Flag.Default).
+func (cmd *Command) MarkPersistentFlagRequired(name string) error {
+	for c := cmd; c != nil; c = c.parent {
+		var flag *Flag
+		for i := range c.PersistentFlags {
+			if c.PersistentFlags[i].Name == name {
+				flag = &c.PersistentFlags[i]
+			}
+		}
+		for i := range c.Flags {
+			if c.Flags[i].Name == name && c.Flags[i].Persistent {
+				flag = &c.Flags[i]
+			}
+		}
+		if flag == nil {
+			continue
+		}
+
+		if flag.Default != "" {
+			return fmt.Errorf("flag %s can't be both required and have a default", name)
+		}
+		flag.Required = true
+		return nil
+	}
+	return fmt.Errorf("persistent flag not defined: %s", name)
+}
+
+// ownFlags returns the flags defined on the command, followed by the
+// persistent flags defined on it.
+func (cmd *Command) ownFlags() []Flag {
+	flags := append([]Flag{}, cmd.Flags...)
+	for _, flag := range cmd.PersistentFlags {
+		flag.Persistent = true
+		flags = append(flags, flag)
+	}
+	return flags
+}
+
+// inheritedFlags returns the persistent flags of the ancestors of the command,
+// excluding those redefined by the command or a closer ancestor.
+func (cmd *Command) inheritedFlags() []Flag {
+	defined := make(map[string]bool)
+	for _, flag := range cmd.ownFlags() {
+		defined[flag.Name] = true
+	}
+
+	var flags []Flag
+	for parent := cmd.parent; parent != nil; parent = parent.parent {
+		for _, flag := range parent.ownFlags() {
+			if !flag.Persistent || defined[flag.Name] {
+				continue
+			}
+			defined[flag.Name] = true
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+// LocalFlags returns the flags defined on the command, followed by the
+// persistent flags defined on it, leaving out hidden ones.
+func (cmd *Command) LocalFlags() []Flag {
+	return visibleFlags(cmd.ownFlags())
+}
+
+// InheritedFlags returns the persistent flags of the ancestors of the command,
+// excluding those redefined by the command or a closer ancestor and hidden
+// ones.
+func (cmd *Command) InheritedFlags() []Flag {
+	return visibleFlags(cmd.inheritedFlags())
+}
+
+// allFlags returns the flags defined on the command followed by the inherited
+// flags.
+func (cmd *Command) allFlags() []Flag {
+	return append(cmd.ownFlags(), cmd.inheritedFlags()...)
+}
+
+// visibleFlags returns the given flags without the hidden ones.
+func visibleFlags(flags []Flag) []Flag {
+	var visible []Flag
+	for _, flag := range flags {
+		if !flag.Hidden {
+			visible = append(visible, flag)
+		}
+	}
+	return visible
+}
+
+// flag returns the flag with the given name or alias, including the inherited
+// flags.
 func (cmd *Command) flag(nameOrAlias string) (Flag, error) {
-	for _, flag := range cmd.Flags {
-		if flag.Name == nameOrAlias {
+	normalize := cmd.normalizeFlag
+	if normalize == nil {
+		normalize = func(name string) string { return name }
+	}
+
+	for _, flag := range cmd.allFlags() {
+		if normalize(flag.Name) == normalize(nameOrAlias) {
 			return flag, nil
 		}
 		for _, alias := range flag.Aliases {
-			if alias == nameOrAlias {
+			if normalize(alias) == normalize(nameOrAlias) {
 				return flag, nil
 			}
 		}
 	}
-	return Flag{}, fmt.Errorf("flag not defined: %s", nameOrAlias)
+	return Flag{}, &flagNotFoundError{name: nameOrAlias}
+}
+
+// flagNotFoundError is returned when a flag is not found.
+type flagNotFoundError struct {
+	name string
+}
+
+func (err *flagNotFoundError) Error() string {
+	return fmt.Sprintf("flag not defined: %s", err.name)
 }
 
 // Help returns a generated help command which prints usage details on run.
+//
+// If the names of child commands are passed (e.g. 'help remote add'), the
+// usage details of that command are printed instead. Otherwise, if the name
+// of a help topic added to the app is passed (e.g. 'help config'), the topic
+// is printed.
 func (cmd *Command) Help() *Command {
 	return &Command{
 		Name:        "help",
 		Aliases:     []string{"h"},
 		Description: "prints description and usage details",
+		Arguments: []Argument{
+			{
+				Name:     "command",
+				Optional: true,
+				Multiple: true,
+			},
+		},
 		Run: func(ctx *Context) error {
-			fmt.Fprintln(ctx.Stdout(), cmd.Usage())
-			return nil
+			names, _ := ctx.ArgumentMultiple("command")
+
+			// Find the command with the given names, starting from
+			// the command the help command was added to
+			target := cmd
+			for _, name := range names {
+				child, err := ctx.app.command(target, name)
+				if err != nil {
+					target = nil
+					break
+				}
+				target = child
+			}
+			if target != nil {
+				return ctx.app.writeUsage(ctx, target)
+			}
+
+			// Fall back to the help topic with the given name
+			if len(names) == 1 {
+				if _, ok := ctx.app.helpTopics[names[0]]; ok {
+					return ctx.app.writeHelpTopic(ctx, names[0])
+				}
+			}
+			return fmt.Errorf("command or help topic not defined: %s", strings.Join(names, " "))
 		},
 	}
 }
 
-// Usage returns the usage details.
+// Usage returns the usage details, with the flags and child commands sorted by
+// name and the long description wrapped to the width of the terminal.
+//
+// The default flags and child commands of the app created with the command
+// tree (e.g. '--version') are included.
 func (cmd *Command) Usage() string {
-	// Find the maximum number of tabs
-	var maxLen int
-	for _, flag := range cmd.Flags {
-		nameAndAliases := flag.nameAndAliases()
-		if len(nameAndAliases) > maxLen {
-			maxLen = len(nameAndAliases)
-		}
+	f := defaultHelpFormatter{app: cmd.rootApp(), sortCommands: true, sortFlags: true}
+	return f.usage(cmd, kuboutil.TerminalWidth())
+}
+
+// run runs the command with the given context, along with the Before and
+// After hooks of the command and its ancestors.
+//
+// If CleanupOnError is set on the app, the After hooks are deferred, so that
+// they are also run if a Before hook returns an error or anything panics.
+func (cmd *Command) run(ctx *Context) (err error) {
+	var chain []*Command
+	for c := cmd; c != nil; c = c.parent {
+		chain = append([]*Command{c}, chain...)
 	}
-	for _, child := range cmd.children {
-		nameAndAliases := child.nameAndAliases()
-		if len(nameAndAliases) > maxLen {
-			maxLen = len(nameAndAliases)
+
+	// runAfter runs the After hooks in reverse order, returning the given
+	// error or otherwise the first error returned by them
+	runAfter := func(err error) error {
+		for i := len(chain) - 1; i >= 0; i-- {
+			if chain[i].After == nil {
+				continue
+			}
+			if afterErr := chain[i].After(ctx); afterErr != nil && err == nil {
+				err = afterErr
+			}
 		}
+		return err
+	}
+	if ctx.app.CleanupOnError {
+		defer func() {
+			err = runAfter(err)
+		}()
 	}
-	maxTabs := maxLen/TabSize + 1
-
-	// usage is where the usage string will be built up
-	var usage strings.Builder
-
-	// Name and description
-	usage.WriteString(fmt.Sprintln("name"))
-	usage.WriteString(fmt.Sprintf("\t%s - %s", cmd.fullName(), cmd.Description))
 
-	// Command usage
-	usage.WriteString("\n\n")
-	usage.WriteString(fmt.Sprintln("usage"))
-	commandUsages := cmd.commandUsages()
-	for i, commandUsage := range commandUsages {
-		usage.WriteString(fmt.Sprintf("\t%s %s", cmd.fullName(), commandUsage))
-		if i != len(commandUsages)-1 {
-			usage.WriteString("\n")
+	for _, c := range chain {
+		if c.Before == nil {
+			continue
+		}
+		if err := c.Before(ctx); err != nil {
+			return err
 		}
 	}
 
-	// Flags
-	if len(cmd.Flags) > 0 {
-		usage.WriteString("\n\n")
-		usage.WriteString(fmt.Sprintln("flags"))
-		for i, flag := range cmd.Flags {
-			nameAndAliases := flag.nameAndAliases()
-			usage.WriteString(fmt.Sprintf(
-				"\t%s%s%s",
-				nameAndAliases,
-				tabs(maxTabs-len(nameAndAliases)/TabSize),
-				flag.Description,
-			))
-			if i != len(cmd.Flags)-1 {
-				usage.WriteString("\n")
-			}
-		}
+	err = cmd.Run(ctx)
+	if ctx.app.CleanupOnError {
+		return err
 	}
+	return runAfter(err)
+}
 
-	// Commands
-	if len(cmd.children) > 0 {
-		usage.WriteString("\n\n")
-		usage.WriteString(fmt.Sprintln("commands"))
-		for i, child := range cmd.children {
-			nameAndAliases := child.nameAndAliases()
-			usage.WriteString(fmt.Sprintf(
-				"\t%s%s%s",
-				nameAndAliases,
-				tabs(maxTabs-len(nameAndAliases)/TabSize),
-				child.Description,
-			))
-			if i != len(cmd.children)-1 {
-				usage.WriteString("\n")
-			}
-		}
+// arguments returns the arguments of the command, from either Arguments or
+// PositionalArgs.
+func (cmd *Command) arguments() []Argument {
+	if cmd.PositionalArgs != nil {
+		return cmd.PositionalArgs
 	}
+	return cmd.Arguments
+}
 
-	return usage.String()
+// Parent returns the parent command, or nil if the command has not been added
+// to one.
+func (cmd *Command) Parent() *Command {
+	return cmd.parent
 }
 
-// fullName returns the full name of the command (including parent names).
-func (cmd *Command) fullName() string {
+// FullName returns the full name of the command (including parent names, e.g.
+// 'tool remote add').
+func (cmd *Command) FullName() string {
 	name := cmd.Name
 	cmd = cmd.parent
 	for cmd != nil {
@@ -163,37 +740,48 @@ func (cmd *Command) fullName() string {
 	return name
 }
 
-// commandUsages returns the specific command usage possibilities.
-func (cmd *Command) commandUsages() []string {
-	var usages []string
-	if len(cmd.Arguments) > 0 {
-		var usage string
-		if cmd.Arguments[0].Multiple {
-			usage = fmt.Sprintf("<%s>...", cmd.Arguments[0].Name)
-		} else {
-			usage = fmt.Sprintf("<%s>", cmd.Arguments[0].Name)
-		}
-		for _, arg := range cmd.Arguments[1:] {
-			fmt.Println(arg)
-			if arg.Multiple {
-				usage = fmt.Sprintf("%s <%s>...", usage, arg.Name)
-			} else {
-				usage = fmt.Sprintf("%s <%s>", usage, arg.Name)
-			}
-		}
-		usages = append(usages, usage)
+// HelpDescription returns the description for the command's own help page,
+// which is Description (or Short if empty) marked if deprecated.
+func (cmd *Command) HelpDescription() string {
+	if cmd.Description == "" {
+		return cmd.deprecatedBadge(cmd.Short)
+	}
+	return cmd.deprecatedBadge(cmd.Description)
+}
+
+// ShortDescription returns the description for listing the command under its
+// parent, which is Short (or Description if empty) marked if deprecated.
+func (cmd *Command) ShortDescription() string {
+	if cmd.Short == "" {
+		return cmd.deprecatedBadge(cmd.Description)
 	}
-	if len(cmd.children) > 0 {
-		usages = append(usages, "<command>")
+	return cmd.deprecatedBadge(cmd.Short)
+}
+
+// deprecatedBadge returns the given description prefixed with a badge if the
+// command is deprecated.
+func (cmd *Command) deprecatedBadge(description string) string {
+	if cmd.Deprecated == "" {
+		return description
 	}
+	return strings.TrimSpace(fmt.Sprintf("[DEPRECATED] %s", description))
+}
 
-	return usages
+// ArgumentsUsage returns the arguments as shown in the command usage (e.g.
+// '<source> [<targets>...]'), or an empty string if there are none.
+func (cmd *Command) ArgumentsUsage() string {
+	var usages []string
+	for _, arg := range cmd.arguments() {
+		usages = append(usages, arg.Usage())
+	}
+	return strings.Join(usages, " ")
 }
 
-func (cmd *Command) nameAndAliases() string {
-	names := []string{cmd.Name}
-	for _, alias := range cmd.Aliases {
-		names = append(names, alias)
+// NameAndAliases returns the name followed by the aliases in parentheses (e.g.
+// 'remove (rm, del)').
+func (cmd *Command) NameAndAliases() string {
+	if len(cmd.Aliases) == 0 {
+		return cmd.Name
 	}
-	return strings.Join(names, ", ")
+	return fmt.Sprintf("%s (%s)", cmd.Name, strings.Join(cmd.Aliases, ", "))
 }
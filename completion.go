@@ -0,0 +1,386 @@
+package kubo
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bashIdentifierRegexp matches the characters that can't be used in bash
+// function names.
+var bashIdentifierRegexp = regexp.MustCompile("[^a-zA-Z0-9_]")
+
+// GenBashCompletionV2 writes a bash completion script for the command to the
+// given writer, treating it as the root command, and an error if it could not
+// be written.
+//
+// The script completes child command names, flags and the allowed values of
+// flags and arguments (leaving out hidden commands and flags), and completes
+// file names for other flag values. If
+// includeDesc is set, the descriptions of the candidates (including those of
+// allowed values given as 'value\tdescription') are shown when there is more
+// than one.
+//
+// The default flags and child commands of the app created with the command
+// tree (e.g. '--version') are also completed, and the candidates are sorted
+// by name unless App.SortCommands or App.SortFlags is unset.
+func (cmd *Command) GenBashCompletionV2(w io.Writer, includeDesc bool) error {
+	return cmd.genBashCompletion(w, includeDesc, cmd.rootApp())
+}
+
+// genBashCompletion writes the bash completion script for the command (see
+// GenBashCompletionV2), including the default flags and child commands of the
+// given app (if any).
+func (cmd *Command) genBashCompletion(w io.Writer, includeDesc bool, app *App) error {
+	function := fmt.Sprintf("_%s_completions", bashIdentifierRegexp.ReplaceAllString(cmd.Name, "_"))
+
+	var script strings.Builder
+	script.WriteString(fmt.Sprintf("# bash completion for %s\n", cmd.Name))
+	script.WriteString(fmt.Sprintf("%s() {\n", function))
+	script.WriteString("\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	script.WriteString("\tlocal prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	script.WriteString(fmt.Sprintf("\tlocal path=%s\n", bashQuote(cmd.Name)))
+
+	// Find the command being completed by following the child command
+	// names typed so far, counting the arguments typed for it
+	script.WriteString("\tlocal i n=0\n")
+	script.WriteString("\tfor ((i = 1; i < COMP_CWORD; i++)); do\n")
+	script.WriteString("\t\tcase \"$path ${COMP_WORDS[i]}\" in\n")
+	cmd.walkCompletions(app, cmd.Name, func(path string, cmd *Command) {
+		for _, child := range cmd.children {
+			var patterns []string
+			for _, name := range append([]string{child.Name}, child.Aliases...) {
+				patterns = append(patterns, bashQuote(fmt.Sprintf("%s %s", path, name)))
+			}
+			script.WriteString(fmt.Sprintf(
+				"\t\t%s) path=%s; n=0 ;;\n",
+				strings.Join(patterns, "|"),
+				bashQuote(fmt.Sprintf("%s %s", path, child.Name)),
+			))
+		}
+		if patterns := valueFlagPatterns(app, path, cmd); len(patterns) > 0 {
+			script.WriteString(fmt.Sprintf("\t\t%s) ((i++)) ;;\n", strings.Join(patterns, "|")))
+		}
+	})
+	script.WriteString("\t\t*) [[ \"${COMP_WORDS[i]}\" != -* ]] && ((n++)) ;;\n")
+	script.WriteString("\t\tesac\n")
+	script.WriteString("\tdone\n\n")
+
+	// Collect the candidates, as lines of the candidate and its description
+	// separated by a tab
+	script.WriteString("\tlocal candidates\n")
+	script.WriteString("\tcase \"$path $prev\" in\n")
+
+	// Complete the allowed values for the values of flags that have them,
+	// and file names for the other flags that are not bool flags
+	cmd.walkCompletions(app, cmd.Name, func(path string, cmd *Command) {
+		for _, flag := range append(cmd.allFlags(), app.DefaultFlags(cmd)...) {
+			if flag.Bool {
+				continue
+			}
+
+			var patterns []string
+			for _, name := range flag.names() {
+				patterns = append(patterns, bashQuote(fmt.Sprintf("%s %s", path, name)))
+			}
+			if len(flag.AllowedValues) > 0 {
+				script.WriteString(fmt.Sprintf(
+					"\t%s) candidates=%s ;;\n",
+					strings.Join(patterns, "|"),
+					bashANSIQuote(strings.Join(flag.AllowedValues, "\n")),
+				))
+			} else {
+				script.WriteString(fmt.Sprintf(
+					"\t%s) COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n",
+					strings.Join(patterns, "|"),
+				))
+			}
+		}
+	})
+
+	// Otherwise, complete the child commands, flags and the allowed values
+	// of the current argument
+	script.WriteString("\t*)\n")
+	script.WriteString("\t\tcase \"$path\" in\n")
+	cmd.walkCompletions(app, cmd.Name, func(path string, cmd *Command) {
+		var candidates []string
+		children, flags := cmd.completionCandidates(app)
+		for _, child := range children {
+			candidates = append(candidates, fmt.Sprintf("%s\t%s", child.Name, child.ShortDescription()))
+		}
+		for _, flag := range flags {
+			for _, name := range flag.names() {
+				candidates = append(candidates, fmt.Sprintf("%s\t%s", name, flag.deprecatedBadge(flag.Description)))
+			}
+		}
+		script.WriteString(fmt.Sprintf(
+			"\t\t%s) candidates=%s ;;\n",
+			bashQuote(path),
+			bashANSIQuote(strings.Join(candidates, "\n")),
+		))
+	})
+	script.WriteString("\t\tesac\n")
+	cmd.walkCompletions(app, cmd.Name, func(path string, cmd *Command) {
+		for i, arg := range cmd.arguments() {
+			if len(arg.AllowedValues) == 0 {
+				continue
+			}
+
+			// Multiple arguments take all the remaining arguments
+			comparison := "-eq"
+			if arg.Multiple {
+				comparison = "-ge"
+			}
+			script.WriteString(fmt.Sprintf(
+				"\t\t[[ \"$path\" == %s && $n %s %d ]] && candidates+=%s\n",
+				bashQuote(path),
+				comparison,
+				i,
+				bashANSIQuote(fmt.Sprintf("\n%s", strings.Join(arg.AllowedValues, "\n"))),
+			))
+		}
+	})
+	script.WriteString("\t\t;;\n")
+	script.WriteString("\tesac\n\n")
+
+	// Filter the candidates by the current word
+	script.WriteString("\tlocal matches=() line\n")
+	script.WriteString("\twhile IFS= read -r line; do\n")
+	script.WriteString("\t\tif [[ -n \"$line\" && \"${line%%$'\\t'*}\" == \"$cur\"* ]]; then\n")
+	script.WriteString("\t\t\tmatches+=(\"$line\")\n")
+	script.WriteString("\t\tfi\n")
+	script.WriteString("\tdone <<< \"$candidates\"\n\n")
+
+	// Only show the descriptions if there is more than one match, since
+	// a single match is inserted as is, and only for the candidates that
+	// have one (values without a tab have none)
+	script.WriteString("\tCOMPREPLY=()\n")
+	script.WriteString("\tfor line in \"${matches[@]}\"; do\n")
+	if includeDesc {
+		script.WriteString("\t\tif [[ ${#matches[@]} -gt 1 && \"$line\" == *$'\\t'* && \"${line#*$'\\t'}\" != \"\" ]]; then\n")
+		script.WriteString("\t\t\tCOMPREPLY+=(\"${line%%$'\\t'*}  (${line#*$'\\t'})\")\n")
+		script.WriteString("\t\telse\n")
+		script.WriteString("\t\t\tCOMPREPLY+=(\"${line%%$'\\t'*}\")\n")
+		script.WriteString("\t\tfi\n")
+	} else {
+		script.WriteString("\t\tCOMPREPLY+=(\"${line%%$'\\t'*}\")\n")
+	}
+	script.WriteString("\tdone\n")
+	script.WriteString("}\n")
+
+	// Keep bash from sorting the candidates unless both are sorted
+	if app != nil && (!app.SortCommands || !app.SortFlags) {
+		script.WriteString(fmt.Sprintf("complete -o nosort -F %s %s\n", function, cmd.Name))
+	} else {
+		script.WriteString(fmt.Sprintf("complete -F %s %s\n", function, cmd.Name))
+	}
+
+	_, err := io.WriteString(w, script.String())
+	return err
+}
+
+// GenZshCompletion writes a zsh completion script for the command to the given
+// writer, treating it as the root command, and an error if it could not be
+// written.
+//
+// The script loads the bash completion script (see GenBashCompletionV2)
+// through zsh's bashcompinit, so it completes the same candidates.
+func (cmd *Command) GenZshCompletion(w io.Writer, includeDesc bool) error {
+	return cmd.genZshCompletion(w, includeDesc, cmd.rootApp())
+}
+
+// genZshCompletion writes the zsh completion script for the command (see
+// GenZshCompletion), including the default flags and child commands of the
+// given app (if any).
+func (cmd *Command) genZshCompletion(w io.Writer, includeDesc bool, app *App) error {
+	if _, err := io.WriteString(w, "#compdef "+cmd.Name+"\nautoload -U +X bashcompinit && bashcompinit\n"); err != nil {
+		return err
+	}
+	return cmd.genBashCompletion(w, includeDesc, app)
+}
+
+// Completion returns a generated completion command which prints the completion
+// script for the given shell ('bash' or 'zsh') on run, treating the command as
+// the root command.
+//
+// If shell is empty, the shell is passed as an argument instead (e.g.
+// 'completion bash'), which otherwise defaults to the given shell.
+func (cmd *Command) Completion(shell string) *Command {
+	return &Command{
+		Name:        "completion",
+		Description: "prints the shell completion script",
+		Arguments: []Argument{
+			{
+				Name:          "shell",
+				Optional:      shell != "",
+				Default:       shell,
+				AllowedValues: []string{"bash", "zsh"},
+			},
+		},
+		Run: func(ctx *Context) error {
+			shell, err := ctx.Argument("shell")
+			if err != nil {
+				return err
+			}
+
+			switch shell {
+			case "bash":
+				return cmd.genBashCompletion(ctx.Stdout(), true, ctx.app)
+			case "zsh":
+				return cmd.genZshCompletion(ctx.Stdout(), true, ctx.app)
+			default:
+				return fmt.Errorf("shell not supported: %s (supported shells: bash, zsh)", shell)
+			}
+		},
+	}
+}
+
+// complete writes the completion candidates for the given command line, up to
+// the given cursor position, to stdout (one per line), and an error if they
+// could not be written.
+//
+// This is used when bash calls the program itself for the candidates (e.g.
+// with 'complete -C tool tool'), passing the command line in COMP_LINE and the
+// cursor position in COMP_POINT. The candidates are the same as those of the
+// script written by GenBashCompletionV2 (if App.SortCommands or App.SortFlags
+// is unset, register it with 'complete -o nosort -C tool tool' to keep their
+// order).
+func (a *App) complete(line, point string) error {
+	// Only complete the command line up to the cursor
+	if n, err := strconv.Atoi(point); err == nil && n >= 0 && n < len(line) {
+		line = line[:n]
+	}
+
+	// The current word is empty if the cursor is after a space
+	words := strings.Fields(line)
+	if len(words) > 0 {
+		words = words[1:]
+	}
+	cur := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		cur = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	// Find the command being completed, along with the flag whose value is
+	// being completed (if any) and the number of arguments before the
+	// current word
+	cmd := a.Root
+	var valueFlag *Flag
+	n := 0
+	for _, word := range words {
+		if valueFlag != nil {
+			valueFlag = nil
+			continue
+		}
+		if name, ok := parseFlagName(word); ok {
+			if flag, err := a.flag(cmd, name); err == nil && !flag.Bool {
+				valueFlag = &flag
+			}
+			continue
+		}
+		if n == 0 {
+			if child, err := a.command(cmd, word); err == nil {
+				cmd = child
+				continue
+			}
+		}
+		n++
+	}
+
+	// Collect the candidates
+	var candidates []string
+	if valueFlag != nil {
+		candidates = valueFlag.allowedValues()
+	} else {
+		children, flags := cmd.completionCandidates(a)
+		if n == 0 {
+			for _, child := range children {
+				candidates = append(candidates, child.Name)
+			}
+		}
+		for _, flag := range flags {
+			candidates = append(candidates, flag.names()...)
+		}
+		for i, arg := range cmd.arguments() {
+			if i == n || (arg.Multiple && i <= n) {
+				for _, value := range arg.AllowedValues {
+					candidates = append(candidates, strings.SplitN(value, "\t", 2)[0])
+				}
+			}
+		}
+	}
+
+	// Filter the candidates by the current word
+	var completions strings.Builder
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, cur) {
+			completions.WriteString(fmt.Sprintln(candidate))
+		}
+	}
+	_, err := io.WriteString(a.Stdout, completions.String())
+	return err
+}
+
+// completionCandidates returns the visible child commands and flags completed
+// for the command, including the default ones of the given app (if any),
+// sorted by name unless the app disables it.
+func (cmd *Command) completionCandidates(app *App) ([]*Command, []Flag) {
+	children := append(cmd.Children(), app.DefaultCommands(cmd)...)
+	if app == nil || app.SortCommands {
+		sort.SliceStable(children, func(i, j int) bool {
+			return children[i].Name < children[j].Name
+		})
+	}
+	flags := visibleFlags(append(cmd.allFlags(), app.DefaultFlags(cmd)...))
+	if app == nil || app.SortFlags {
+		sort.SliceStable(flags, func(i, j int) bool {
+			return flags[i].Name < flags[j].Name
+		})
+	}
+	return children, flags
+}
+
+// walkCompletions calls the given function with the command and all of its
+// descendants (including the default child commands of the given app), along
+// with their paths starting from the given path.
+func (cmd *Command) walkCompletions(app *App, path string, fn func(path string, cmd *Command)) {
+	fn(path, cmd)
+	for _, child := range append(cmd.AllChildren(), app.DefaultCommands(cmd)...) {
+		child.walkCompletions(app, fmt.Sprintf("%s %s", path, child.Name), fn)
+	}
+}
+
+// valueFlagPatterns returns the bash case patterns matching the names of the
+// flags of the given command (including the default flags of the given app)
+// that are not bool flags.
+func valueFlagPatterns(app *App, path string, cmd *Command) []string {
+	var patterns []string
+	for _, flag := range append(cmd.allFlags(), app.DefaultFlags(cmd)...) {
+		if flag.Bool {
+			continue
+		}
+		for _, name := range flag.names() {
+			patterns = append(patterns, bashQuote(fmt.Sprintf("%s %s", path, name)))
+		}
+	}
+	return patterns
+}
+
+// bashQuote returns the given string in single quotes for use in bash.
+func bashQuote(s string) string {
+	return fmt.Sprintf("'%s'", strings.Replace(s, "'", `'\''`, -1))
+}
+
+// bashANSIQuote returns the given string in ANSI-C quotes (e.g. $'a\tb') for
+// use in bash.
+func bashANSIQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, "'", `\'`, -1)
+	s = strings.Replace(s, "\t", `\t`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return fmt.Sprintf("$'%s'", s)
+}
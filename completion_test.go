@@ -0,0 +1,194 @@
+package kubo_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ravernkoh/kubo"
+	"github.com/ravernkoh/kubo/kubotest"
+)
+
+func TestCompletionSort(t *testing.T) {
+	root := &kubo.Command{
+		Name: "root",
+		Flags: []kubo.Flag{
+			kubo.BoolFlag("zoo", "the zoo"),
+			kubo.BoolFlag("ant", "the ant"),
+		},
+		Run: func(ctx *kubo.Context) error { return nil },
+	}
+	root.Add(&kubo.Command{Name: "beta", Run: func(ctx *kubo.Context) error { return nil }})
+	root.Add(&kubo.Command{Name: "alpha", Run: func(ctx *kubo.Context) error { return nil }})
+	app := kubo.NewApp(root)
+
+	t.Setenv("COMP_LINE", "root ")
+	t.Setenv("COMP_POINT", "5")
+
+	kubotest.New(app).Run().AssertStdout(t, "alpha\nbeta\n--ant\n--zoo\n")
+
+	app.SortCommands = false
+	app.SortFlags = false
+	kubotest.New(app).Run().AssertStdout(t, "beta\nalpha\n--zoo\n--ant\n")
+}
+
+func TestGenBashCompletionNoSort(t *testing.T) {
+	root := &kubo.Command{Name: "root"}
+	root.Add(&kubo.Command{Name: "child"})
+	app := kubo.NewApp(root)
+
+	var script bytes.Buffer
+	if err := root.GenBashCompletionV2(&script, false); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !strings.Contains(script.String(), "complete -F _root_completions root\n") {
+		t.Errorf("expected the candidates to be sorted, got:\n%s", script.String())
+	}
+
+	app.SortCommands = false
+	script.Reset()
+	if err := root.GenBashCompletionV2(&script, false); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !strings.Contains(script.String(), "complete -o nosort -F _root_completions root\n") {
+		t.Errorf("expected the candidates to be left unsorted, got:\n%s", script.String())
+	}
+}
+
+// completionRoot returns a command tree for testing completion.
+func completionRoot() *kubo.Command {
+	root := &kubo.Command{
+		Name: "tool",
+		Flags: []kubo.Flag{
+			{Name: "format", Type: "format", AllowedValues: []string{"json\tJSON output", "csv"}},
+			{Name: "config", Type: "file"},
+			{Name: "debug", Hidden: true, Bool: true},
+		},
+		Run: func(ctx *kubo.Context) error { return nil },
+	}
+	root.Add(&kubo.Command{
+		Name:        "remote",
+		Aliases:     []string{"r"},
+		Description: "manages remotes",
+		Arguments:   []kubo.Argument{{Name: "kind", AllowedValues: []string{"git", "hg"}}},
+		Run:         func(ctx *kubo.Context) error { return nil },
+	})
+	root.Add(&kubo.Command{Name: "secret", Hidden: true, Run: func(ctx *kubo.Context) error { return nil }})
+	return root
+}
+
+func TestGenBashCompletionV2(t *testing.T) {
+	tests := []struct {
+		includeDesc bool
+		contains    []string
+	}{
+		{false, []string{
+			"_tool_completions() {\n",
+			"\t\t'tool remote'|'tool r') path='tool remote'; n=0 ;;\n",
+			"\t'tool --format') candidates=$'json\\tJSON output\\ncsv' ;;\n",
+			"\t'tool --config') COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n",
+			"\t\t'tool') candidates=$'remote\\tmanages remotes\\n--config\\t\\n--format\\t' ;;\n",
+			"\t\t[[ \"$path\" == 'tool remote' && $n -eq 0 ]] && candidates+=$'\\ngit\\nhg'\n",
+			"\t\tCOMPREPLY+=(\"${line%%$'\\t'*}\")\n\tdone\n",
+			"complete -F _tool_completions tool\n",
+		}},
+		{true, []string{
+			"\t\t\tCOMPREPLY+=(\"${line%%$'\\t'*}  (${line#*$'\\t'})\")\n",
+		}},
+	}
+
+	for _, test := range tests {
+		root := completionRoot()
+		kubo.NewApp(root)
+
+		var script bytes.Buffer
+		if err := root.GenBashCompletionV2(&script, test.includeDesc); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		for _, contains := range test.contains {
+			if !strings.Contains(script.String(), contains) {
+				t.Errorf("includeDesc %v: expected the script to contain %q, got:\n%s", test.includeDesc, contains, script.String())
+			}
+		}
+		if strings.Contains(script.String(), "secret\t") || strings.Contains(script.String(), "--debug\t") {
+			t.Errorf("includeDesc %v: expected hidden commands and flags to be left out, got:\n%s", test.includeDesc, script.String())
+		}
+	}
+}
+
+func TestGenZshCompletion(t *testing.T) {
+	root := completionRoot()
+	kubo.NewApp(root)
+
+	var bash, zsh bytes.Buffer
+	if err := root.GenBashCompletionV2(&bash, true); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := root.GenZshCompletion(&zsh, true); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expected := "#compdef tool\nautoload -U +X bashcompinit && bashcompinit\n" + bash.String()
+	if zsh.String() != expected {
+		t.Errorf("expected %q, got %q", expected, zsh.String())
+	}
+}
+
+func TestRunCompletion(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+		valid    bool
+	}{
+		{[]string{"completion", "bash"}, "# bash completion for tool\n", true},
+		{[]string{"completion", "zsh"}, "#compdef tool\n", true},
+		{[]string{"completion", "fish"}, "", false},
+	}
+
+	for _, test := range tests {
+		root := completionRoot()
+		root.Add(root.Completion(""))
+
+		res := kubotest.New(kubo.NewApp(root)).Run(test.args...)
+		if !test.valid {
+			if res.Err == nil {
+				t.Errorf("%v: expected an error, got none", test.args)
+			}
+			continue
+		}
+		res.AssertExitOK(t)
+		if !strings.HasPrefix(res.Stdout, test.expected) {
+			t.Errorf("%v: expected stdout starting with %q, got %q", test.args, test.expected, res.Stdout)
+		}
+	}
+}
+
+func TestRunCompLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		point    string
+		expected string
+	}{
+		{"tool ", "", "remote\n--config\n--format\n"},
+		{"tool re", "", "remote\n"},
+		{"tool --f", "", "--format\n"},
+		{"tool --format ", "", "json\ncsv\n"},
+		{"tool --format j", "", "json\n"},
+		{"tool r ", "", "git\nhg\n"},
+		{"tool remote git ", "", ""},
+		{"tool se", "", ""},
+		{"tool remote --format csv", "7", "remote\n"},
+	}
+
+	for _, test := range tests {
+		point := test.point
+		if point == "" {
+			point = fmt.Sprint(len(test.line))
+		}
+		t.Setenv("COMP_LINE", test.line)
+		t.Setenv("COMP_POINT", point)
+
+		kubotest.New(kubo.NewApp(completionRoot())).Run().AssertStdout(t, test.expected)
+	}
+}
@@ -1,8 +1,13 @@
 package kubo
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 )
 
 // Context represents the runtime context of a command.
@@ -10,11 +15,30 @@ type Context struct {
 	arguments map[string]string
 	flags     map[string]string
 
+	flagsMultiple map[string][]string
+	flagsPassed   map[string]bool
+
 	argumentMultipleName  string
 	argumentMultipleValue []string
 
+	args            []string
+	nargs           int
+	passthroughArgs []string
+	help            bool
+	version         bool
+	dumpDot         bool
+	warnings        []string
+
 	stdin  io.Reader
 	stdout io.Writer
+	stderr io.Writer
+
+	app     *App
+	command *Command
+
+	values map[interface{}]interface{}
+
+	goCtx *goContext
 }
 
 // Argument returns the argument with the given name and an error if it doesn't
@@ -29,6 +53,9 @@ func (ctx *Context) Argument(name string) (string, error) {
 
 // ArgumentMultiple returns the argument with the given name as a collected
 // argument and an error if it doesn't exist.
+//
+// If the argument is optional and was omitted, the collected values are empty
+// (or only its default value if it has one).
 func (ctx *Context) ArgumentMultiple(name string) ([]string, error) {
 	if ctx.argumentMultipleName != name || ctx.argumentMultipleValue == nil {
 		return nil, fmt.Errorf("multiple argument not found: %s", name)
@@ -36,6 +63,59 @@ func (ctx *Context) ArgumentMultiple(name string) ([]string, error) {
 	return ctx.argumentMultipleValue, nil
 }
 
+// Args returns the extra arguments supplied after all the arguments have been
+// parsed.
+func (ctx *Context) Args() []string {
+	return ctx.args
+}
+
+// AppVersion returns the version of the app (see App.Version).
+func (ctx *Context) AppVersion() string {
+	return ctx.app.Version
+}
+
+// Command returns the command being run, which is the same regardless of
+// whether it was run by its name or an alias.
+func (ctx *Context) Command() *Command {
+	return ctx.command
+}
+
+// Encode encodes the given value using the encoder registered for the given
+// format and writes it to stdout, returning an error listing the valid formats
+// if the format is not registered.
+//
+// This is typically used with the value of an output format flag.
+func (ctx *Context) Encode(format string, v interface{}) error {
+	enc, err := encoder(ctx.app.encoders, format)
+	if err != nil {
+		return err
+	}
+	return enc(v, ctx.stdout)
+}
+
+// EncodeOutput is like Encode, but uses the format passed to the output flag
+// (see App.EnableOutputFlag).
+func (ctx *Context) EncodeOutput(v interface{}) error {
+	format, err := ctx.Flag("output")
+	if err != nil {
+		return err
+	}
+	return ctx.Encode(format, v)
+}
+
+// GoContext returns a context.Context that is cancelled once the command has
+// run or when the app receives an interrupt or termination signal, derived
+// from the context set with App.UseContext.
+//
+// The signals are only handled once this is called. After the first one, they
+// are handled as usual again (e.g. a second interrupt terminates the app).
+func (ctx *Context) GoContext() context.Context {
+	if ctx.goCtx == nil {
+		return context.Background()
+	}
+	return ctx.goCtx.get()
+}
+
 // Flag returns the argument with the given name and an error if it doesn't
 // exist.
 func (ctx *Context) Flag(name string) (string, error) {
@@ -46,12 +126,122 @@ func (ctx *Context) Flag(name string) (string, error) {
 	return arg, nil
 }
 
+// FlagMultiple returns all the values of the flag with the given name and an
+// error if it doesn't exist.
+//
+// This is meant for flags with Multiple set, but a flag passed once (or set to
+// its default value) returns a single value.
+func (ctx *Context) FlagMultiple(name string) ([]string, error) {
+	if values, ok := ctx.flagsMultiple[name]; ok {
+		return values, nil
+	}
+	value, err := ctx.Flag(name)
+	if err != nil {
+		return nil, err
+	}
+	return []string{value}, nil
+}
+
+// NArgs returns the number of arguments supplied, including the extra
+// arguments.
+func (ctx *Context) NArgs() int {
+	return ctx.nargs
+}
+
+// NFlag returns the number of flags passed in the raw arguments, not counting
+// the flags set by their environment variables or default values.
+func (ctx *Context) NFlag() int {
+	return len(ctx.flagsPassed)
+}
+
 // Stdin returns the stdin defined in the app.
 func (ctx *Context) Stdin() io.Reader {
 	return ctx.stdin
 }
 
-// Stdout returns the stdout defined in the app.
+// Stdout returns the stdout set on the command, or otherwise the stdout defined
+// in the app.
 func (ctx *Context) Stdout() io.Writer {
 	return ctx.stdout
 }
+
+// Stderr returns the stderr set on the command, or otherwise the stderr defined
+// in the app (or os.Stderr if there is none).
+func (ctx *Context) Stderr() io.Writer {
+	if ctx.stderr == nil {
+		return os.Stderr
+	}
+	return ctx.stderr
+}
+
+// WithValue returns a copy of the context with the given key mapped to the
+// given value, leaving the context itself unchanged.
+func (ctx *Context) WithValue(key, val interface{}) *Context {
+	child := *ctx
+	child.values = make(map[interface{}]interface{}, len(ctx.values)+1)
+	for k, v := range ctx.values {
+		child.values[k] = v
+	}
+	child.values[key] = val
+	return &child
+}
+
+// Value returns the value mapped to the given key, or nil if there is none.
+func (ctx *Context) Value(key interface{}) interface{} {
+	return ctx.values[key]
+}
+
+// Set maps the given key to the given value in the context itself, which is
+// seen by the Before hooks, Run and After hooks run after it (e.g. to pass
+// loaded configuration from a Before hook to Run).
+func (ctx *Context) Set(key string, val interface{}) {
+	if ctx.values == nil {
+		ctx.values = make(map[interface{}]interface{})
+	}
+	ctx.values[key] = val
+}
+
+// Get returns the value set for the given key, or nil if there is none.
+func (ctx *Context) Get(key string) interface{} {
+	return ctx.values[key]
+}
+
+// GetOr returns the value set for the given key, or the given fallback if
+// there is none.
+func (ctx *Context) GetOr(key string, fallback interface{}) interface{} {
+	val, ok := ctx.values[key]
+	if !ok {
+		return fallback
+	}
+	return val
+}
+
+// goContext lazily derives a context.Context cancelled on signals from the
+// context of a running command.
+type goContext struct {
+	parent context.Context
+
+	once sync.Once
+	ctx  context.Context
+}
+
+// get returns the derived context, handling the signals on the first call.
+func (gc *goContext) get() context.Context {
+	gc.once.Do(func() {
+		ctx, cancel := context.WithCancel(gc.parent)
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			select {
+			case <-signals:
+			case <-ctx.Done():
+			}
+			signal.Stop(signals)
+			cancel()
+		}()
+
+		gc.ctx = ctx
+	})
+	return gc.ctx
+}
@@ -21,6 +21,13 @@
 //  	fmt.Printf("error: %v\n", err)
 //  }
 //
+// Alternatively, `Execute` runs the app with `os.Args`, prints the error and
+// returns the exit code (2 for invalid arguments and the code of a
+// `kuboutil.ExitError` if one is returned).
+//
+//  code, _ := app.Execute()
+//  os.Exit(code)
+//
 // In this case, the app simply prints `"hello, world!"` (more will be explained on
 // the context later).
 //
@@ -44,6 +51,19 @@
 // The `Run` function is the function that will be called if the raw arguments are
 // successfully parsed by the app. Usually, code will be written in this function.
 //
+// Commands can also have a `Short` description, which is shown when the command is
+// listed under its parent. If both are set, `Short` is used in the listing and
+// `Description` is used on the command's own help page.
+//
+//  kubo.Command{
+//  	Name: "commands",
+//  	Short: "a random command",
+//  	Description: "a random command which prints 'random'",
+//  }
+//
+// *Note that `Short` should be preferred over `Description` going forward, as it
+// mirrors the convention used by other command line packages.*
+//
 // Flags
 //
 // Defining flags on a command is easy.
@@ -98,6 +118,30 @@
 //
 //  $ flags -o value1 --two
 //
+// Flags can also have a `Default` value, which is used if the flag is not passed,
+// and a `Validator`, which checks the value before the command is run. For flags
+// with typed values, constructors such as `IntFlag`, `Float64Flag`, `DurationFlag`,
+// `StringFlag` and `BoolFlag` set these up.
+//
+//  kubo.IntFlag("count", "the number of times", 3)
+//
+// Flags with `EnvVar` set fall back to the value of that environment variable if
+// they are not passed, before falling back to `Default`. For flags with `Multiple`
+// set, `EnvVarSeparator` splits the value into multiple values (e.g. `","` for
+// `TAGS=go,cli`).
+//
+// Flags with `Required` set must be passed, otherwise an error is returned before
+// the command is run.
+//
+// Flags with `Persistent` set are inherited by all child commands (and their
+// children), unless they define a flag with the same name.
+//
+//  kubo.Flag{Name: "verbose", Bool: true, Persistent: true}
+//
+// Alternatively, such flags can be defined in `PersistentFlags` instead of `Flags`.
+// A persistent flag can be made required later with `MarkPersistentFlagRequired`,
+// which also finds flags defined on the ancestors of the command.
+//
 // Arguments
 //
 // Defining arguments on a command is also easy.
@@ -139,6 +183,29 @@
 //
 // This will result in `two` having the value `["value2", "value3", "value4"]`.
 //
+// Arguments at the end of the argument list can also be `Optional`, in which case
+// they can be omitted. The value of an omitted argument is its `Default` (or empty
+// if it is not set).
+//
+//  kubo.Argument{
+//  	Name: "two",
+//  	Optional: true,
+//  	Default: "value2",
+//  }
+//
+//  $ arguments value1
+//
+// This will result in `two` having the value `"value2"`.
+//
+// Like flags, arguments can have a `Validator` (e.g. `kuboutil.IsInt`), which checks
+// each value before the command is run.
+//
+// Everything after `--` is parsed as arguments, even if it looks like a flag.
+//
+//  $ arguments value1 -- --value2
+//
+// This will result in `two` having the value `"--value2"`.
+//
 // Contexts
 //
 // The context passed in the run function is used to get the arguments and flags
@@ -170,13 +237,19 @@
 //  	},
 //  }
 //
-// The context also contains the methods for `Stdin` and `Stdout`, which *should* be
-// used to read from and write to the console. They can be configured in the app
-// itself (which will pass these values to the context).
+// The context also contains the methods for `Stdin`, `Stdout` and `Stderr`, which
+// *should* be used to read from and write to the console. They can be configured in
+// the app itself (which will pass these values to the context).
 //
 //  // Default values
 //  app.Stdin = os.Stdin
 //  app.Stdout = os.Stdout
+//  app.Stderr = os.Stderr
+//
+// For testing, `RunWithInput` runs the app with the given stdin and returns what
+// was written to stdout and stderr instead.
+//
+//  stdout, stderr, err := app.RunWithInput([]string{"contexts", "value"}, strings.NewReader(""))
 //
 // When getting arguments and flags from the context, sometimes their values need
 // to be converted to other types. For that purpose, the `kuboutil` package can be
@@ -249,4 +322,24 @@
 // The help command can be called using `help`.
 //
 //  $ complex help
+//
+// Passing `--help` or `-h` to any command also prints its usage details, unless
+// the command defines a flag with the same name.
+//
+//  $ complex --help
+//
+// This can be turned off by setting `DisableDefaultHelpFlag` on the app.
+//
+//  app.DisableDefaultHelpFlag = true
+//
+// Version
+//
+// Setting `Version` on the app makes `--version` and `version` print it, unless
+// the root command defines a flag or child command with the same name. The
+// `version` command is left out if the root command has arguments.
+//
+//  app.Version = "1.2.3"
+//
+//  $ complex --version
+//  complex version 1.2.3
 package kubo
@@ -0,0 +1,54 @@
+package kubo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// EncoderFunc encodes the given value and writes it to the given writer.
+type EncoderFunc func(v interface{}, w io.Writer) error
+
+// defaultEncoders are the encoders available in every app.
+var defaultEncoders = map[string]EncoderFunc{
+	"json": encodeJSON,
+}
+
+// encodeJSON encodes the given value as indented JSON.
+func encodeJSON(v interface{}, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// encoder returns the encoder registered for the given format, falling back to
+// the default encoders, and an error listing the valid formats if there is
+// none.
+func encoder(encoders map[string]EncoderFunc, format string) (EncoderFunc, error) {
+	if enc, ok := encoders[format]; ok {
+		return enc, nil
+	}
+	if enc, ok := defaultEncoders[format]; ok {
+		return enc, nil
+	}
+
+	return nil, fmt.Errorf("format not defined: %s (valid formats: %s)", format, strings.Join(formats(encoders), ", "))
+}
+
+// formats returns the names of the given encoders and the default encoders,
+// sorted alphabetically.
+func formats(encoders map[string]EncoderFunc) []string {
+	var formats []string
+	for format := range defaultEncoders {
+		formats = append(formats, format)
+	}
+	for format := range encoders {
+		if _, ok := defaultEncoders[format]; !ok {
+			formats = append(formats, format)
+		}
+	}
+	sort.Strings(formats)
+	return formats
+}
@@ -2,7 +2,11 @@ package kubo
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/ravernkoh/kubo/kuboutil"
 )
 
 // Flag represents a flag for a command.
@@ -16,10 +20,243 @@ type Flag struct {
 	// If this is set, this flag will be used as a boolean flag (e.g.
 	// 'command --flag'), which means it does not need a value after it.
 	Bool bool
+
+	// Multiple is whether the flag can be passed multiple times, collecting
+	// all of its values (e.g. '--tag a --tag b').
+	//
+	// The values can be retrieved with Context.FlagMultiple, while
+	// Context.Flag returns the last value.
+	Multiple bool
+
+	// Type is the type of the flag value shown in the usage details (e.g.
+	// 'int').
+	Type string
+
+	// Default is the value of the flag if it is not passed, which is shown in
+	// the usage details.
+	//
+	// A flag with a default can't also be required.
+	Default string
+
+	// EnvVar is the name of the environment variable whose value is used if
+	// the flag is not passed, before falling back to Default.
+	EnvVar string
+
+	// EnvVarSeparator is the separator used to split the value of EnvVar into
+	// the values of a Multiple flag (e.g. ',' for 'TAGS=go,cli'), as if the
+	// flag was passed once for each. Whitespace around the values is trimmed
+	// and empty values are dropped.
+	//
+	// If it is empty, the value is used as a single value.
+	EnvVarSeparator string
+
+	// Required is whether the flag must be passed, in which case an error is
+	// returned before the command is run if it is not.
+	//
+	// This also applies to bool flags, which are then required to be passed
+	// explicitly.
+	Required bool
+
+	// Secret is whether the value of the flag is sensitive (e.g. a password),
+	// in which case it is masked as '***' in the usage details and error
+	// messages. The value in the context is not masked.
+	Secret bool
+
+	// Validator validates the value of the flag when it is passed (e.g.
+	// kuboutil.IsInt).
+	//
+	// Any error returned is propagated and returned to the main Run function
+	// of the app, before the command is run.
+	Validator func(string) error
+
+	// AllowedValues are the values the flag can have, which are listed in the
+	// usage details and offered by shell completion.
+	//
+	// If they are set, passing any other value returns an error before the
+	// command is run, and Default must be one of them.
+	//
+	// A value can be followed by a tab and a description (e.g. 'json\tJSON
+	// output'), which is shown alongside it when completing.
+	AllowedValues []string
+
+	// Deprecated is the reason the flag is deprecated (e.g. 'use --new
+	// instead').
+	//
+	// If it is set, the flag is marked as deprecated in the usage details and
+	// a warning is printed to stderr when it is passed.
+	Deprecated string
+
+	// Hidden is whether the flag is left out of the usage details and shell
+	// completion. It can still be passed as usual.
+	Hidden bool
+
+	// Persistent is whether the flag is inherited by all descendants of the
+	// command it is defined on (e.g. a '--verbose' flag on the root command).
+	//
+	// Descendants that define a flag with the same name use their own
+	// definition instead.
+	Persistent bool
+
+	// AllowNegation is whether the bool flag can be cleared by passing it
+	// with a 'no-' prefix (e.g. '--no-verbose'), with the last one passed
+	// taking effect.
+	AllowNegation bool
+
+	// ShowNegation is whether the negated name is shown in the usage details
+	// and offered by shell completion, if AllowNegation is set.
+	ShowNegation bool
 }
 
-// nameAndAliases returns the name and aliases as a comma separated string
-func (flag *Flag) nameAndAliases() string {
+// IntFlag returns a flag with an int value and the given default.
+func IntFlag(name, description string, defaultVal int) Flag {
+	return Flag{
+		Name:        name,
+		Description: description,
+		Type:        "int",
+		Default:     strconv.Itoa(defaultVal),
+		Validator:   kuboutil.IsInt,
+	}
+}
+
+// Float64Flag returns a flag with a float64 value and the given default.
+func Float64Flag(name, description string, defaultVal float64) Flag {
+	return Flag{
+		Name:        name,
+		Description: description,
+		Type:        "float",
+		Default:     strconv.FormatFloat(defaultVal, 'g', -1, 64),
+		Validator:   kuboutil.IsFloat,
+	}
+}
+
+// DurationFlag returns a flag with a duration value (e.g. '1m30s' or '2d') and
+// the given default.
+func DurationFlag(name, description string, defaultVal time.Duration) Flag {
+	return Flag{
+		Name:        name,
+		Description: description,
+		Type:        "duration",
+		Default:     defaultVal.String(),
+		Validator:   isDuration,
+	}
+}
+
+// StringFlag returns a flag with a string value and the given default.
+func StringFlag(name, description, defaultVal string) Flag {
+	return Flag{
+		Name:        name,
+		Description: description,
+		Type:        "string",
+		Default:     defaultVal,
+	}
+}
+
+// BoolFlag returns a bool flag, which is false unless it is passed.
+func BoolFlag(name, description string) Flag {
+	return Flag{
+		Name:        name,
+		Description: description,
+		Bool:        true,
+	}
+}
+
+// isDuration returns an error if the given value is not a duration (see
+// kuboutil.ParseDuration).
+func isDuration(v string) error {
+	if _, err := kuboutil.ParseDuration(v); err != nil {
+		return fmt.Errorf("%s is not a duration", v)
+	}
+	return nil
+}
+
+// Usage returns the name, aliases and type as shown in the usage details.
+func (flag *Flag) Usage() string {
+	if flag.Type == "" {
+		return flag.nameAndAliases()
+	}
+	return fmt.Sprintf("%s <%s>", flag.nameAndAliases(), flag.Type)
+}
+
+// HelpDescription returns the description as shown in the usage details, marked
+// if deprecated and followed by the allowed values, environment variable and
+// default value if there are any.
+func (flag *Flag) HelpDescription() string {
+	description := flag.deprecatedBadge(flag.Description)
+	if flag.EnvVar != "" {
+		description = fmt.Sprintf("%s (env: %s)", description, flag.EnvVar)
+	}
+	if allowedValues := flag.allowedValues(); len(allowedValues) > 0 {
+		description = fmt.Sprintf("%s (allowed: %s)", description, strings.Join(allowedValues, ", "))
+	}
+	if flag.Default != "" {
+		description = fmt.Sprintf("%s (default: %s)", description, flag.mask(flag.Default))
+	}
+	return strings.TrimSpace(description)
+}
+
+// deprecatedBadge returns the given description prefixed with a badge if the
+// flag is deprecated.
+func (flag *Flag) deprecatedBadge(description string) string {
+	if flag.Deprecated == "" {
+		return description
+	}
+	return strings.TrimSpace(fmt.Sprintf("[DEPRECATED] %s", description))
+}
+
+// allowedValues returns the allowed values without their descriptions.
+func (flag *Flag) allowedValues() []string {
+	var values []string
+	for _, value := range flag.AllowedValues {
+		values = append(values, strings.SplitN(value, "\t", 2)[0])
+	}
+	return values
+}
+
+// checkAllowedValue returns an error if the flag has allowed values and the
+// given value is not one of them, with the value masked if the flag is secret.
+func (flag *Flag) checkAllowedValue(value string) error {
+	allowedValues := flag.allowedValues()
+	if len(allowedValues) == 0 {
+		return nil
+	}
+	for _, allowedValue := range allowedValues {
+		if value == allowedValue {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not one of [%s]", flag.mask(value), strings.Join(allowedValues, ", "))
+}
+
+// validate returns an error if the given value is rejected by the validator of
+// the flag (if any).
+//
+// If the flag is secret, the error of the validator is replaced, since it may
+// contain the value.
+func (flag *Flag) validate(value string) error {
+	if flag.Validator == nil {
+		return nil
+	}
+	if err := flag.Validator(value); err != nil {
+		if flag.Secret {
+			return fmt.Errorf("%s is not valid", flag.mask(value))
+		}
+		return err
+	}
+	return nil
+}
+
+// mask returns the given value as shown in the usage details and error
+// messages, which is '***' if the flag is secret.
+func (flag *Flag) mask(value string) string {
+	if flag.Secret {
+		return "***"
+	}
+	return value
+}
+
+// names returns the name and aliases as they are passed (e.g. '--flag' and
+// '-f').
+func (flag *Flag) names() []string {
 	names := []string{fmt.Sprintf("--%s", flag.Name)}
 	for _, alias := range flag.Aliases {
 		if len(alias) > 1 {
@@ -28,5 +265,13 @@ func (flag *Flag) nameAndAliases() string {
 			names = append(names, fmt.Sprintf("-%s", alias))
 		}
 	}
-	return strings.Join(names, ", ")
+	if flag.Bool && flag.AllowNegation && flag.ShowNegation {
+		names = append(names, fmt.Sprintf("--no-%s", flag.Name))
+	}
+	return names
+}
+
+// nameAndAliases returns the name and aliases as a comma separated string
+func (flag *Flag) nameAndAliases() string {
+	return strings.Join(flag.names(), ", ")
 }
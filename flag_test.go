@@ -0,0 +1,182 @@
+package kubo_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ravernkoh/kubo"
+	"github.com/ravernkoh/kubo/kubotest"
+	"github.com/ravernkoh/kubo/kuboutil"
+)
+
+func TestFlagSecret(t *testing.T) {
+	root := &kubo.Command{
+		Name: "root",
+		Flags: []kubo.Flag{
+			{Name: "pin", Secret: true, Validator: kuboutil.IsInt},
+			{Name: "token", Secret: true, Default: "hunter2"},
+		},
+	}
+
+	_, err := kubo.Parse(root, []string{"root", "--pin", "1a"})
+	if err == nil {
+		t.Fatal("expected an error, got none")
+	}
+	if expected := "invalid value for flag pin: *** is not valid"; err.Error() != expected {
+		t.Fatalf("expected error %q, got %q", expected, err)
+	}
+
+	usage := root.Usage()
+	if strings.Contains(usage, "hunter2") || !strings.Contains(usage, "(default: ***)") {
+		t.Fatalf("expected the default to be masked, got %q", usage)
+	}
+}
+
+func TestFlagAllowedValues(t *testing.T) {
+	tests := []struct {
+		flag     kubo.Flag
+		value    string
+		expected string
+	}{
+		{kubo.Flag{Name: "format", AllowedValues: []string{"json", "yaml\tYAML output"}}, "json", ""},
+		{kubo.Flag{Name: "format", AllowedValues: []string{"json", "yaml\tYAML output"}}, "yaml", ""},
+		{kubo.Flag{Name: "format", AllowedValues: []string{"json", "yaml\tYAML output"}}, "xml", `flag --format: "xml" is not one of [json, yaml]`},
+		{kubo.Flag{Name: "key", Secret: true, AllowedValues: []string{"abc", "def"}}, "a", `flag --key: "***" is not one of [abc, def]`},
+	}
+
+	for _, test := range tests {
+		root := &kubo.Command{Name: "root", Flags: []kubo.Flag{test.flag}}
+		_, err := kubo.Parse(root, []string{"root", "--" + test.flag.Name, test.value})
+		if test.expected == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, got: %v", test.value, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("%s: expected error %q, got: %v", test.value, test.expected, err)
+		}
+	}
+}
+
+func TestMarkPersistentFlagRequired(t *testing.T) {
+	root := &kubo.Command{
+		Name: "root",
+		Flags: []kubo.Flag{
+			{Name: "token", Persistent: true},
+			{Name: "local"},
+		},
+		PersistentFlags: []kubo.Flag{
+			{Name: "region", Default: "eu"},
+		},
+	}
+	child := &kubo.Command{
+		Name: "child",
+		Run:  func(ctx *kubo.Context) error { return nil },
+	}
+	root.Add(child)
+	app := kubo.NewApp(root)
+
+	if err := child.MarkPersistentFlagRequired("token"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, name := range []string{"local", "region", "missing"} {
+		if err := child.MarkPersistentFlagRequired(name); err == nil {
+			t.Errorf("%s: expected an error, got none", name)
+		}
+	}
+
+	if res := kubotest.New(app).Run("child"); res.Err == nil || !strings.Contains(res.Err.Error(), "flag --token is required") {
+		t.Errorf("expected the flag to be required, got: %v", res.Err)
+	}
+	kubotest.New(app).Run("child", "--token", "x").AssertExitOK(t)
+}
+
+func TestFlagEnvVarSeparator(t *testing.T) {
+	tests := []struct {
+		flag     kubo.Flag
+		env      string
+		expected string
+		valid    bool
+	}{
+		{kubo.Flag{Name: "tag", EnvVar: "KUBO_TEST_TAGS", Multiple: true, EnvVarSeparator: ","}, "go, cli,,tools", "[go cli tools] tools\n", true},
+		{kubo.Flag{Name: "tag", EnvVar: "KUBO_TEST_TAGS", Multiple: true, EnvVarSeparator: ";"}, "go,cli;tools", "[go,cli tools] tools\n", true},
+		{kubo.Flag{Name: "tag", EnvVar: "KUBO_TEST_TAGS", Multiple: true}, "go,cli", "[go,cli] go,cli\n", true},
+		{kubo.Flag{Name: "tag", EnvVar: "KUBO_TEST_TAGS", EnvVarSeparator: ","}, "go,cli", "[go,cli] go,cli\n", true},
+		{kubo.Flag{Name: "tag", EnvVar: "KUBO_TEST_TAGS", Multiple: true, EnvVarSeparator: ",", AllowedValues: []string{"go", "cli"}}, "go,tools", "", false},
+	}
+
+	for _, test := range tests {
+		t.Setenv("KUBO_TEST_TAGS", test.env)
+		root := &kubo.Command{
+			Name:  "root",
+			Flags: []kubo.Flag{test.flag},
+			Run: func(ctx *kubo.Context) error {
+				tags, err := ctx.FlagMultiple("tag")
+				if err != nil {
+					return err
+				}
+				tag, err := ctx.Flag("tag")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(ctx.Stdout(), "%v %s\n", tags, tag)
+				return nil
+			},
+		}
+
+		res := kubotest.New(kubo.NewApp(root)).Run()
+		if !test.valid {
+			if res.Err == nil {
+				t.Errorf("%q: expected an error, got none", test.env)
+			}
+			continue
+		}
+		res.AssertStdout(t, test.expected)
+	}
+}
+
+func TestDurationFlag(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		valid    bool
+	}{
+		{"", "1m30s\n", true},
+		{"2h", "2h0m0s\n", true},
+		{"2d", "48h0m0s\n", true},
+		{"1w1d", "192h0m0s\n", true},
+		{"10", "", false},
+		{"soon", "", false},
+	}
+
+	for _, test := range tests {
+		root := &kubo.Command{
+			Name:  "root",
+			Flags: []kubo.Flag{kubo.DurationFlag("timeout", "the timeout", 90*time.Second)},
+			Run: func(ctx *kubo.Context) error {
+				timeout, err := kuboutil.Duration(ctx.Flag("timeout"))
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(ctx.Stdout(), timeout)
+				return nil
+			},
+		}
+
+		args := []string{}
+		if test.value != "" {
+			args = append(args, "--timeout", test.value)
+		}
+		res := kubotest.New(kubo.NewApp(root)).Run(args...)
+		if !test.valid {
+			if res.Err == nil {
+				t.Errorf("%q: expected an error, got none", test.value)
+			}
+			continue
+		}
+		res.AssertStdout(t, test.expected)
+	}
+}
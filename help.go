@@ -0,0 +1,221 @@
+package kubo
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ravernkoh/kubo/kuboutil"
+)
+
+// HelpFormatter formats the usage details of commands when printing help.
+//
+// Commands expose their details through their fields and methods such as
+// FullName, HelpDescription, ArgumentsUsage, LocalFlags, InheritedFlags and
+// Children, and flags through Usage and HelpDescription. The default flags and
+// child commands (e.g. '--version') and the help topics are given by
+// App.DefaultFlags, App.DefaultCommands and App.HelpTopics, so a formatter
+// that needs them should keep the app it is set on. The default formatter is
+// built from these alone.
+type HelpFormatter interface {
+	// Format writes the usage details of the given command to the given
+	// writer, and returns an error if they could not be written.
+	Format(cmd *Command, w io.Writer) error
+}
+
+// defaultHelpFormatter formats the usage details as by Command.Usage, with the
+// child commands and flags sorted and the global flags hidden if specified.
+// The long description is wrapped to the width of the writer.
+//
+// The default flags and child commands of the app (if any) are included, and
+// the names of its help topics are listed at the end of the usage details of
+// the root command.
+type defaultHelpFormatter struct {
+	app             *App
+	sortCommands    bool
+	sortFlags       bool
+	hideGlobalFlags bool
+}
+
+func (f defaultHelpFormatter) Format(cmd *Command, w io.Writer) error {
+	var usage strings.Builder
+	usage.WriteString(f.usage(cmd, kuboutil.TerminalWidthOf(w)))
+
+	// Help topics
+	if f.app != nil && cmd.Parent() == nil {
+		topics := f.app.HelpTopics()
+		if len(topics) > 0 {
+			usage.WriteString("\n\n")
+			usage.WriteString(fmt.Sprintln("help topics"))
+			for i, topic := range topics {
+				usage.WriteString(fmt.Sprintf("\t%s", topic))
+				if i != len(topics)-1 {
+					usage.WriteString("\n")
+				}
+			}
+		}
+	}
+
+	_, err := fmt.Fprintln(w, usage.String())
+	return err
+}
+
+// usage returns the usage details of the given command, with the long
+// description wrapped to the given width.
+func (f defaultHelpFormatter) usage(cmd *Command, width int) string {
+	flags := append(cmd.LocalFlags(), f.app.DefaultFlags(cmd)...)
+	var inheritedFlags []Flag
+	if !f.hideGlobalFlags {
+		inheritedFlags = cmd.InheritedFlags()
+	}
+	if f.sortFlags {
+		sort.SliceStable(flags, func(i, j int) bool {
+			return flags[i].Name < flags[j].Name
+		})
+		sort.SliceStable(inheritedFlags, func(i, j int) bool {
+			return inheritedFlags[i].Name < inheritedFlags[j].Name
+		})
+	}
+	children := append(cmd.Children(), f.app.DefaultCommands(cmd)...)
+	if f.sortCommands {
+		sort.SliceStable(children, func(i, j int) bool {
+			return children[i].Name < children[j].Name
+		})
+	}
+
+	// Find the maximum number of tabs
+	var maxLen int
+	for _, flag := range append(append([]Flag{}, flags...), inheritedFlags...) {
+		flagUsage := flag.Usage()
+		if len(flagUsage) > maxLen {
+			maxLen = len(flagUsage)
+		}
+	}
+	for _, child := range children {
+		nameAndAliases := child.NameAndAliases()
+		if len(nameAndAliases) > maxLen {
+			maxLen = len(nameAndAliases)
+		}
+	}
+	maxTabs := maxLen/TabSize + 1
+
+	// usage is where the usage string will be built up
+	var usage strings.Builder
+
+	// Name and description
+	usage.WriteString(fmt.Sprintln("name"))
+	usage.WriteString(fmt.Sprintf("\t%s - %s", cmd.FullName(), cmd.HelpDescription()))
+
+	// Long description, indented by a tab
+	if cmd.LongDescription != "" {
+		usage.WriteString("\n")
+		for _, line := range wrap(strings.TrimSpace(cmd.LongDescription), width-TabSize) {
+			if line == "" {
+				usage.WriteString("\n")
+				continue
+			}
+			usage.WriteString(fmt.Sprintf("\n\t%s", line))
+		}
+	}
+
+	// Command usage
+	var commandUsages []string
+	if argumentsUsage := cmd.ArgumentsUsage(); argumentsUsage != "" {
+		commandUsages = append(commandUsages, argumentsUsage)
+	}
+	if len(children) > 0 {
+		commandUsages = append(commandUsages, "<command>")
+	}
+	usage.WriteString("\n\n")
+	usage.WriteString(fmt.Sprintln("usage"))
+	for i, commandUsage := range commandUsages {
+		usage.WriteString(fmt.Sprintf("\t%s %s", cmd.FullName(), commandUsage))
+		if i != len(commandUsages)-1 {
+			usage.WriteString("\n")
+		}
+	}
+
+	// Flags
+	if len(flags) > 0 {
+		usage.WriteString("\n\n")
+		usage.WriteString(fmt.Sprintln("flags"))
+		for i, flag := range flags {
+			flagUsage := flag.Usage()
+			usage.WriteString(fmt.Sprintf(
+				"\t%s%s%s",
+				flagUsage,
+				tabs(maxTabs-len(flagUsage)/TabSize),
+				flag.HelpDescription(),
+			))
+			if i != len(flags)-1 {
+				usage.WriteString("\n")
+			}
+		}
+	}
+
+	// Inherited flags
+	if len(inheritedFlags) > 0 {
+		usage.WriteString("\n\n")
+		usage.WriteString(fmt.Sprintln("global flags"))
+		for i, flag := range inheritedFlags {
+			flagUsage := flag.Usage()
+			usage.WriteString(fmt.Sprintf(
+				"\t%s%s%s",
+				flagUsage,
+				tabs(maxTabs-len(flagUsage)/TabSize),
+				flag.HelpDescription(),
+			))
+			if i != len(inheritedFlags)-1 {
+				usage.WriteString("\n")
+			}
+		}
+	}
+
+	// Commands, by group
+	groups, groupedChildren := commandGroups(children)
+	for _, group := range groups {
+		header := "commands"
+		if len(groups) > 1 {
+			header = group
+			if group == "" {
+				header = "other commands"
+			}
+		}
+
+		usage.WriteString("\n\n")
+		usage.WriteString(fmt.Sprintln(header))
+		for i, child := range groupedChildren[group] {
+			nameAndAliases := child.NameAndAliases()
+			usage.WriteString(fmt.Sprintf(
+				"\t%s%s%s",
+				nameAndAliases,
+				tabs(maxTabs-len(nameAndAliases)/TabSize),
+				child.ShortDescription(),
+			))
+			if i != len(groupedChildren[group])-1 {
+				usage.WriteString("\n")
+			}
+		}
+	}
+
+	return usage.String()
+}
+
+// commandGroups groups the given commands by Group, returning the groups in
+// the order they are first seen (with the empty group last) and the commands
+// in each group in their given order.
+func commandGroups(cmds []*Command) ([]string, map[string][]*Command) {
+	var groups []string
+	grouped := make(map[string][]*Command)
+	for _, cmd := range cmds {
+		if _, ok := grouped[cmd.Group]; !ok && cmd.Group != "" {
+			groups = append(groups, cmd.Group)
+		}
+		grouped[cmd.Group] = append(grouped[cmd.Group], cmd)
+	}
+	if _, ok := grouped[""]; ok {
+		groups = append(groups, "")
+	}
+	return groups, grouped
+}
@@ -0,0 +1,165 @@
+package kubo_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ravernkoh/kubo"
+	"github.com/ravernkoh/kubo/kubotest"
+)
+
+// listFormatter lists the details given to help formatters, one per line.
+type listFormatter struct {
+	app *kubo.App
+}
+
+func (f listFormatter) Format(cmd *kubo.Command, w io.Writer) error {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("%s - %s", cmd.FullName(), cmd.HelpDescription()))
+	lines = append(lines, fmt.Sprintf("usage: %s", cmd.ArgumentsUsage()))
+	for _, flag := range append(cmd.LocalFlags(), f.app.DefaultFlags(cmd)...) {
+		lines = append(lines, fmt.Sprintf("flag: %s - %s", flag.Usage(), flag.HelpDescription()))
+	}
+	for _, flag := range cmd.InheritedFlags() {
+		lines = append(lines, fmt.Sprintf("global flag: %s - %s", flag.Usage(), flag.HelpDescription()))
+	}
+	for _, child := range append(cmd.Children(), f.app.DefaultCommands(cmd)...) {
+		lines = append(lines, fmt.Sprintf("command: %s - %s", child.NameAndAliases(), child.ShortDescription()))
+	}
+	for _, topic := range f.app.HelpTopics() {
+		lines = append(lines, fmt.Sprintf("topic: %s", topic))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+func TestHelpFormatter(t *testing.T) {
+	root := &kubo.Command{
+		Name:            "root",
+		Description:     "the root",
+		PersistentFlags: []kubo.Flag{kubo.BoolFlag("verbose", "be verbose")},
+		Run:             func(ctx *kubo.Context) error { return nil },
+	}
+	child := &kubo.Command{
+		Name:        "child",
+		Aliases:     []string{"c"},
+		Description: "the child",
+		Short:       "a child",
+		Arguments:   []kubo.Argument{{Name: "files", Multiple: true}},
+		Flags:       []kubo.Flag{{Name: "hidden", Hidden: true}},
+		Run:         func(ctx *kubo.Context) error { return nil },
+	}
+	root.Add(child)
+	app := kubo.NewApp(root)
+	app.Version = "1.0.0"
+	app.AddHelpTopic("config", "the config")
+	app.HelpFormatter = listFormatter{app: app}
+
+	kubotest.New(app).Run("--help").AssertStdout(t, strings.Join([]string{
+		"root - the root",
+		"usage: ",
+		"flag: --verbose - be verbose",
+		"flag: --version - prints the version",
+		"command: child (c) - a child",
+		"command: version - prints the version",
+		"topic: config",
+	}, "\n")+"\n")
+
+	kubotest.New(app).Run("child", "--help").AssertStdout(t, strings.Join([]string{
+		"root child - the child",
+		"usage: <files>...",
+		"global flag: --verbose - be verbose",
+		"topic: config",
+	}, "\n")+"\n")
+}
+
+func TestHelpCommand(t *testing.T) {
+	root := &kubo.Command{
+		Name: "root",
+		Run:  func(ctx *kubo.Context) error { return nil },
+	}
+	remote := &kubo.Command{Name: "remote", Description: "manages remotes"}
+	remote.Add(&kubo.Command{
+		Name:        "add",
+		Description: "adds a remote",
+		Run:         func(ctx *kubo.Context) error { return nil },
+	})
+	root.Add(remote)
+	root.Add(root.Help())
+	app := kubo.NewApp(root)
+	app.AddHelpTopic("config", "the config")
+	app.AddHelpTopic("remote", "the remote topic")
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"help", "remote"}, "\troot remote - manages remotes"},
+		{[]string{"help", "remote", "add"}, "\troot remote add - adds a remote"},
+		{[]string{"help", "config"}, "the config\n"},
+	}
+
+	for _, test := range tests {
+		res := kubotest.New(app).Run(test.args...)
+		res.AssertExitOK(t)
+		if !strings.Contains(res.Stdout, test.expected) {
+			t.Errorf("%v: expected stdout containing %q, got %q", test.args, test.expected, res.Stdout)
+		}
+	}
+
+	if res := kubotest.New(app).Run("help", "unknown"); res.Err == nil {
+		t.Error("expected an error, got none")
+	}
+}
+
+func TestHelpDeprecated(t *testing.T) {
+	root := &kubo.Command{
+		Name: "root",
+		Flags: []kubo.Flag{
+			{Name: "old", Description: "the old flag", Deprecated: "use --new instead"},
+		},
+		Run: func(ctx *kubo.Context) error { return nil },
+	}
+	root.Add(&kubo.Command{
+		Name:        "legacy",
+		Description: "the legacy command",
+		Deprecated:  "use modern instead",
+		Run:         func(ctx *kubo.Context) error { return nil },
+	})
+	app := kubo.NewApp(root)
+
+	var script strings.Builder
+	if err := root.GenBashCompletionV2(&script, true); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	res := kubotest.New(app).Run("--help")
+	res.AssertExitOK(t)
+
+	for _, output := range []string{res.Stdout, script.String()} {
+		for _, expected := range []string{"[DEPRECATED] the old flag", "[DEPRECATED] the legacy command"} {
+			if !strings.Contains(output, expected) {
+				t.Errorf("expected %q in:\n%s", expected, output)
+			}
+		}
+	}
+}
+
+func TestHelpLongDescriptionWrap(t *testing.T) {
+	for _, word := range []string{"abcde", "äbcdé", "日本語です"} {
+		root := &kubo.Command{
+			Name:            "root",
+			LongDescription: strings.TrimSpace(strings.Repeat(word+" ", 30)),
+			Run:             func(ctx *kubo.Context) error { return nil },
+		}
+
+		// The long description is wrapped to the default width of 80, less
+		// the indentation, which fits 12 words of 5 runes
+		res := kubotest.New(kubo.NewApp(root)).Run("--help")
+		expected := "\t" + strings.TrimSpace(strings.Repeat(word+" ", 12)) + "\n"
+		if !strings.Contains(res.Stdout, expected) {
+			t.Errorf("%q: expected a line of 12 words, got:\n%s", word, res.Stdout)
+		}
+	}
+}
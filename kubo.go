@@ -1,8 +1,10 @@
 package kubo
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 )
 
 // TabSize is the number of spaces a tab occupies.
@@ -17,6 +19,32 @@ func tabs(n int) string {
 	return tabs.String()
 }
 
+// wrap splits the given text into lines of at most the given width (counted in
+// runes), wrapping at spaces. Existing line breaks are kept, and lines starting
+// with whitespace (e.g. indented examples) are kept as is.
+func wrap(text string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		words := strings.Fields(line)
+		if len(words) == 0 || strings.TrimLeft(line, " \t") != line {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+			continue
+		}
+
+		wrapped := words[0]
+		for _, word := range words[1:] {
+			if utf8.RuneCountInString(wrapped)+1+utf8.RuneCountInString(word) > width {
+				lines = append(lines, wrapped)
+				wrapped = word
+				continue
+			}
+			wrapped = fmt.Sprintf("%s %s", wrapped, word)
+		}
+		lines = append(lines, wrapped)
+	}
+	return lines
+}
+
 // Regexps for parsing flags
 var (
 	longFlagRegexp  = regexp.MustCompile("^--([a-zA-Z0-9][a-zA-Z0-9\\-_]*[a-zA-Z0-9])$")
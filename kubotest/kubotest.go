@@ -0,0 +1,82 @@
+// Package kubotest provides utilities for testing command line apps built with
+// kubo, by running them in-process and checking their output.
+//
+//	r := kubotest.New(app)
+//	res := r.Run("greet", "--name", "world")
+//	res.AssertExitOK(t)
+//	res.AssertStdout(t, "hello, world\n")
+package kubotest
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ravernkoh/kubo"
+)
+
+// Runner runs an app in-process, capturing what it writes.
+type Runner struct {
+	// Stdin is read from by the app, which is empty if it is nil.
+	Stdin io.Reader
+
+	app *kubo.App
+}
+
+// New creates a new runner for the given app.
+func New(app *kubo.App) *Runner {
+	return &Runner{
+		app: app,
+	}
+}
+
+// Run runs the app with the given arguments (without the program name) and
+// returns the result.
+//
+// The app itself is not modified.
+func (r *Runner) Run(args ...string) *Result {
+	stdin := r.Stdin
+	if stdin == nil {
+		stdin = strings.NewReader("")
+	}
+
+	stdout, stderr, err := r.app.RunWithInput(append([]string{r.app.Root.Name}, args...), stdin)
+	return &Result{
+		Stdout: stdout,
+		Stderr: stderr,
+		Err:    err,
+	}
+}
+
+// Result represents the result of running an app.
+type Result struct {
+	Stdout string
+	Stderr string
+	Err    error // returned by the app
+}
+
+// AssertExitOK fails the test if the app returned an error.
+func (res *Result) AssertExitOK(t testing.TB) {
+	t.Helper()
+	if res.Err != nil {
+		t.Fatalf("expected no error, got: %v", res.Err)
+	}
+}
+
+// AssertStdout fails the test if what the app wrote to stdout is not the given
+// string.
+func (res *Result) AssertStdout(t testing.TB, expected string) {
+	t.Helper()
+	if res.Stdout != expected {
+		t.Fatalf("expected stdout %q, got %q", expected, res.Stdout)
+	}
+}
+
+// AssertStderr fails the test if what the app wrote to stderr doesn't contain
+// the given string.
+func (res *Result) AssertStderr(t testing.TB, contains string) {
+	t.Helper()
+	if !strings.Contains(res.Stderr, contains) {
+		t.Fatalf("expected stderr to contain %q, got %q", contains, res.Stderr)
+	}
+}
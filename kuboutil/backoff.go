@@ -0,0 +1,39 @@
+package kuboutil
+
+import (
+	"context"
+	"time"
+)
+
+// Backoff calls the given function up to the given number of attempts until it
+// succeeds, waiting between attempts starting from the given delay and
+// doubling it each time.
+//
+// If all the attempts fail, the error from the last attempt is returned. The
+// function is always called at least once.
+func Backoff(attempts int, delay time.Duration, fn func() error) error {
+	return BackoffContext(context.Background(), attempts, delay, fn)
+}
+
+// BackoffContext is like Backoff, but stops waiting and returns the error of
+// the given context once it is done.
+func BackoffContext(ctx context.Context, attempts int, delay time.Duration, fn func() error) error {
+	var err error
+	for i := 0; i < attempts || i == 0; i++ {
+		if i > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			delay *= 2
+		}
+
+		if err = fn(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package kuboutil
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// WriteCSV writes the given header and rows as CSV to the given writer and an
+// error if they could not be written.
+func WriteCSV(w io.Writer, header []string, rows [][]string) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+	if err := csvWriter.WriteAll(rows); err != nil {
+		return err
+	}
+	return nil
+}
+
+// csvEncoder encodes structs as CSV.
+type csvEncoder struct {
+	w io.Writer
+}
+
+// CSVEncoder returns an encoder that writes a struct or a slice of structs as
+// CSV to the given writer, which can be used to register a 'csv' format on the
+// app.
+//
+//	app.RegisterEncoder("csv", func(v interface{}, w io.Writer) error {
+//		return kuboutil.CSVEncoder(w).Encode(v)
+//	})
+//
+// The exported fields of the struct are used as the columns, with the field
+// names as the header. The name can be changed using a 'csv' tag, and fields
+// with the tag 'csv:"-"' are skipped.
+func CSVEncoder(w io.Writer) interface{ Encode(v interface{}) error } {
+	return &csvEncoder{w: w}
+}
+
+// Encode writes the given struct or slice of structs as CSV and an error if it
+// could not be encoded or written.
+func (enc *csvEncoder) Encode(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+
+	var values []reflect.Value
+	switch rv.Kind() {
+	case reflect.Struct:
+		values = append(values, rv)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			values = append(values, reflect.Indirect(rv.Index(i)))
+		}
+	default:
+		return fmt.Errorf("could not encode %T as csv", v)
+	}
+
+	// Find the columns from the struct type
+	var typ reflect.Type
+	if rv.Kind() == reflect.Struct {
+		typ = rv.Type()
+	} else {
+		typ = rv.Type().Elem()
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+	}
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("could not encode %T as csv", v)
+	}
+
+	var (
+		header []string
+		fields []int
+	)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("csv"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+
+	var rows [][]string
+	for _, value := range values {
+		if !value.IsValid() {
+			continue
+		}
+
+		var row []string
+		for _, i := range fields {
+			row = append(row, fmt.Sprint(value.Field(i).Interface()))
+		}
+		rows = append(rows, row)
+	}
+
+	return WriteCSV(enc.w, header, rows)
+}
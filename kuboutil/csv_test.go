@@ -0,0 +1,59 @@
+package kuboutil
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []string{"name", "note"}, [][]string{{"a", "x, y"}, {"b", `say "hi"`}}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expected := "name,note\na,\"x, y\"\nb,\"say \"\"hi\"\"\"\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestCSVEncoder(t *testing.T) {
+	type item struct {
+		Name    string
+		Count   int    `csv:"count"`
+		Skipped string `csv:"-"`
+		private string
+	}
+
+	tests := []struct {
+		v        interface{}
+		expected string
+		valid    bool
+	}{
+		{item{Name: "a", Count: 1}, "Name,count\na,1\n", true},
+		{&item{Name: "a", Count: 1}, "Name,count\na,1\n", true},
+		{[]item{{Name: "a", Count: 1}, {Name: "b", Count: 2}}, "Name,count\na,1\nb,2\n", true},
+		{[]*item{{Name: "a"}, nil}, "Name,count\na,0\n", true},
+		{[]item{}, "Name,count\n", true},
+		{"a", "", false},
+		{[]int{1}, "", false},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		err := CSVEncoder(&buf).Encode(test.v)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("Encode(%#v): expected an error, got none", test.v)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Encode(%#v): expected no error, got: %v", test.v, err)
+			continue
+		}
+		if buf.String() != test.expected {
+			t.Errorf("Encode(%#v): expected %q, got %q", test.v, test.expected, buf.String())
+		}
+	}
+}
@@ -1,4 +1,5 @@
-// Package kuboutil provides type conversion utilities for flags.
+// Package kuboutil provides utilities for command line apps, mainly type
+// conversion utilities for flags.
 //
 // The code for getting the value of flags usually looks like this
 //
@@ -0,0 +1,60 @@
+package kuboutil
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	// durationRegexp matches a duration made up of numbers followed by units
+	// (e.g. '1w2d3h').
+	durationRegexp = regexp.MustCompile(`^[-+]?(?:(?:[0-9]+\.?[0-9]*|\.[0-9]+)[a-zµμ]+)+$`)
+
+	// durationPartRegexp matches a number followed by a unit in a duration.
+	durationPartRegexp = regexp.MustCompile(`([0-9]+\.?[0-9]*|\.[0-9]+)([a-zµμ]+)`)
+)
+
+// ParseDuration parses the given duration like time.ParseDuration, but also
+// accepts days ('d') and weeks ('w') as units (e.g. '1w2d12h').
+//
+// Unlike time.ParseDuration, numbers without a unit (including '0') return an
+// error.
+func ParseDuration(s string) (time.Duration, error) {
+	if !durationRegexp.MatchString(s) {
+		return 0, fmt.Errorf("could not convert %s to a duration", s)
+	}
+
+	// Sum up the days and weeks separately, and leave the rest of the
+	// parts to time.ParseDuration
+	var days float64
+	var rest string
+	for _, part := range durationPartRegexp.FindAllStringSubmatch(s, -1) {
+		switch part[2] {
+		case "d":
+			n, _ := strconv.ParseFloat(part[1], 64)
+			days += n
+		case "w":
+			n, _ := strconv.ParseFloat(part[1], 64)
+			days += n * 7
+		default:
+			rest += part[0]
+		}
+	}
+
+	var d time.Duration
+	if rest != "" {
+		var err error
+		d, err = time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("could not convert %s to a duration", s)
+		}
+	}
+	d += time.Duration(days * float64(24*time.Hour))
+
+	if s[0] == '-' {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,46 @@
+package kuboutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected time.Duration
+		valid    bool
+	}{
+		{"1h30m", 90 * time.Minute, true},
+		{"500ms", 500 * time.Millisecond, true},
+		{"2d", 48 * time.Hour, true},
+		{"1w", 7 * 24 * time.Hour, true},
+		{"1w2d3h", 9*24*time.Hour + 3*time.Hour, true},
+		{"1.5d", 36 * time.Hour, true},
+		{"-1d2h", -26 * time.Hour, true},
+		{"+2h", 2 * time.Hour, true},
+		{"0", 0, false},
+		{"10", 0, false},
+		{"", 0, false},
+		{"1x", 0, false},
+		{"d", 0, false},
+		{"1h 2m", 0, false},
+	}
+
+	for _, test := range tests {
+		actual, err := ParseDuration(test.s)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("ParseDuration(%q): expected an error, got none", test.s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDuration(%q): expected no error, got: %v", test.s, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("ParseDuration(%q): expected %v, got %v", test.s, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package kuboutil
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadEnvFile parses the .env file at the given path and returns its variables
+// and an error if it can't be read or parsed.
+//
+// Each line should be in the form 'KEY=VALUE', optionally prefixed by 'export'.
+// Blank lines and lines starting with '#' are ignored, and values surrounded
+// by matching single or double quotes are unquoted. The environment itself is
+// not modified.
+func LoadEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open env file %s", path)
+	}
+	defer f.Close()
+
+	env := make(map[string]string)
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		i := strings.Index(line, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("could not parse env file %s: line %d", path, n)
+		}
+
+		key := strings.TrimSpace(line[:i])
+		if key == "" {
+			return nil, fmt.Errorf("could not parse env file %s: line %d", path, n)
+		}
+
+		value := strings.TrimSpace(line[i+1:])
+		if len(value) > 1 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		env[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read env file %s", path)
+	}
+
+	return env, nil
+}
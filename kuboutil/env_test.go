@@ -0,0 +1,53 @@
+package kuboutil
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadEnvFile(t *testing.T) {
+	tests := []struct {
+		contents string
+		expected map[string]string
+		valid    bool
+	}{
+		{"", map[string]string{}, true},
+		{"A=1\nB=two", map[string]string{"A": "1", "B": "two"}, true},
+		{"# comment\n\n  A = 1  \n", map[string]string{"A": "1"}, true},
+		{"export A=1", map[string]string{"A": "1"}, true},
+		{`A="a b"` + "\n" + `B='c d'`, map[string]string{"A": "a b", "B": "c d"}, true},
+		{`A="a'`, map[string]string{"A": `"a'`}, true},
+		{"A=", map[string]string{"A": ""}, true},
+		{"A=b=c", map[string]string{"A": "b=c"}, true},
+		{"A", nil, false},
+		{"=1", nil, false},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), ".env")
+		if err := os.WriteFile(path, []byte(test.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		actual, err := LoadEnvFile(path)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("LoadEnvFile(%q): expected an error, got none", test.contents)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("LoadEnvFile(%q): expected no error, got: %v", test.contents, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("LoadEnvFile(%q): expected %v, got %v", test.contents, test.expected, actual)
+		}
+	}
+
+	if _, err := LoadEnvFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadEnvFile(missing): expected an error, got none")
+	}
+}
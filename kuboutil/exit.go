@@ -0,0 +1,32 @@
+package kuboutil
+
+import "fmt"
+
+// ExitError is an error with the exit code the app should exit with (see
+// kubo.App.Execute).
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// NewExitError returns an error wrapping the given error with the given exit
+// code.
+func NewExitError(code int, err error) *ExitError {
+	return &ExitError{
+		Code: code,
+		Err:  err,
+	}
+}
+
+func (err *ExitError) Error() string {
+	if err.Err == nil {
+		return fmt.Sprintf("exit status %d", err.Code)
+	}
+	return err.Err.Error()
+}
+
+// Unwrap returns the wrapped error, so that it can be matched with errors.Is
+// and errors.As.
+func (err *ExitError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,107 @@
+package kuboutil
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileOrStdin opens the file at the given path for reading, or returns the
+// given stdin (with a Close method that does nothing) if the path is '-', and
+// an error if the file can't be opened.
+func FileOrStdin(path string, stdin io.Reader) (io.ReadCloser, error) {
+	if path == "-" {
+		return ioutil.NopCloser(stdin), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fileError(path, err)
+	}
+	return f, nil
+}
+
+// FileOrStdout creates or truncates the file at the given path for writing,
+// or returns the given stdout (with a Close method that does nothing) if the
+// path is '-', and an error if the file can't be opened.
+func FileOrStdout(path string, stdout io.Writer) (io.WriteCloser, error) {
+	return fileOrStdout(path, stdout, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+}
+
+// FileOrStdoutAppend is like FileOrStdout, but opens the file for appending
+// instead of truncating it.
+func FileOrStdoutAppend(path string, stdout io.Writer) (io.WriteCloser, error) {
+	return fileOrStdout(path, stdout, os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+}
+
+// fileOrStdout opens the file at the given path with the given flags, or
+// returns the given stdout if the path is '-'.
+func fileOrStdout(path string, stdout io.Writer, flag int) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopCloser{stdout}, nil
+	}
+
+	f, err := os.OpenFile(path, flag, 0666)
+	if err != nil {
+		return nil, fileError(path, err)
+	}
+	return f, nil
+}
+
+// EnsureDir creates the directory of the file at the given path, along with
+// any parents, if it doesn't exist, and returns an error if it can't be
+// created.
+func EnsureDir(path string) error {
+	return os.MkdirAll(filepath.Dir(path), 0777)
+}
+
+// WriteFile writes the given data to the file at the given path with the given
+// permissions, creating its directory if needed.
+//
+// The data is written to a temporary file in the same directory which then
+// replaces the file, so that the file is never partially written.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	if err := EnsureDir(path); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), fmt.Sprintf(".%s.tmp", filepath.Base(path)))
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// fileError returns an error describing why the file at the given path could
+// not be opened.
+func fileError(path string, err error) error {
+	switch {
+	case os.IsNotExist(err):
+		return fmt.Errorf("file not found: %s", path)
+	case os.IsPermission(err):
+		return fmt.Errorf("permission denied: %s", path)
+	default:
+		return fmt.Errorf("could not open file %s: %v", path, err)
+	}
+}
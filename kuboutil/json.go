@@ -0,0 +1,47 @@
+package kuboutil
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSON returns the given value encoded as JSON and an error if it can't be
+// encoded.
+func JSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// PrettyJSON returns the given value encoded as JSON indented with two spaces
+// and an error if it can't be encoded.
+func PrettyJSON(v interface{}) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// MustJSON is like JSON, but panics if the value can't be encoded.
+//
+// It is meant for values known to be encodable (e.g. structs with only
+// encodable fields), since failing to encode them is a programming error.
+func MustJSON(v interface{}) string {
+	s, err := JSON(v)
+	if err != nil {
+		panic(fmt.Errorf("kuboutil: could not encode %T as JSON: %v", v, err))
+	}
+	return s
+}
+
+// MustPrettyJSON is like PrettyJSON, but panics if the value can't be encoded.
+func MustPrettyJSON(v interface{}) string {
+	s, err := PrettyJSON(v)
+	if err != nil {
+		panic(fmt.Errorf("kuboutil: could not encode %T as JSON: %v", v, err))
+	}
+	return s
+}
@@ -3,6 +3,7 @@ package kuboutil
 import (
 	"fmt"
 	"strconv"
+	"time"
 )
 
 // Int returns the given string as an int and an error if it can't be converted
@@ -35,6 +36,30 @@ func Float32(v string, err error) (float32, error) {
 	return float32(f), nil
 }
 
+// Float64 returns the given string as a float64 and an error if it can't be
+// converted or if an error was given.
+func Float64(v string, err error) (float64, error) {
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not convert %s to an float64", v)
+	}
+
+	return f, nil
+}
+
+// Duration returns the given string as a time.Duration (see ParseDuration) and
+// an error if it can't be converted or if an error was given.
+func Duration(v string, err error) (time.Duration, error) {
+	if err != nil {
+		return 0, err
+	}
+	return ParseDuration(v)
+}
+
 // Bool returns the given string as an bool and an error if it can't be converted
 // or if an error was given.
 func Bool(v string, err error) (bool, error) {
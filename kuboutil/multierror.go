@@ -0,0 +1,14 @@
+package kuboutil
+
+import "strings"
+
+// MultiError is a collection of errors returned as a single error.
+type MultiError []error
+
+func (errs MultiError) Error() string {
+	var msgs []string
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
@@ -0,0 +1,74 @@
+package kuboutil
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// defaultPager is the pager used if $PAGER is not set.
+const defaultPager = "less"
+
+// pager pipes everything written to it to a pager process.
+type pager struct {
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+// Close closes the pipe to the pager and waits for it to exit.
+func (p *pager) Close() error {
+	if err := p.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return p.cmd.Wait()
+}
+
+// nopCloser wraps a writer with a Close method that does nothing.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// NewPager returns a writer that pipes to the pager in $PAGER (or less if not
+// set) which displays on the given writer.
+//
+// If the given writer is not a terminal or the pager can't be started, the
+// given writer is returned as is (with a Close method that does nothing).
+// Otherwise, Close must be called to wait for the pager to exit.
+func NewPager(w io.Writer) io.WriteCloser {
+	f, ok := w.(*os.File)
+	if !ok || !IsTTY(f) {
+		return nopCloser{w}
+	}
+
+	command := strings.Fields(os.Getenv("PAGER"))
+	if len(command) == 0 {
+		command = []string{defaultPager}
+	}
+	if _, err := exec.LookPath(command[0]); err != nil {
+		return nopCloser{w}
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdout = f
+	cmd.Stderr = os.Stderr
+
+	// Let less exit if the output fits on one screen, like git does
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nopCloser{w}
+	}
+	if err := cmd.Start(); err != nil {
+		return nopCloser{w}
+	}
+
+	return &pager{WriteCloser: stdin, cmd: cmd}
+}
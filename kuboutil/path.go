@@ -0,0 +1,31 @@
+package kuboutil
+
+import (
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandHome returns the given path with a leading '~' replaced by the home
+// directory of the current user (e.g. '~/.config' becomes
+// '/home/user/.config').
+//
+// The path is returned as is if it doesn't start with '~' or the home
+// directory can't be determined.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
+	}
+
+	u, err := user.Current()
+	if err != nil || u.HomeDir == "" {
+		return path
+	}
+	return filepath.Join(u.HomeDir, path[1:])
+}
+
+// Abs returns the absolute form of the given path with a leading '~' expanded
+// (see ExpandHome), and an error if it can't be determined.
+func Abs(path string) (string, error) {
+	return filepath.Abs(ExpandHome(path))
+}
@@ -0,0 +1,30 @@
+package kuboutil
+
+import "strings"
+
+// RedactedArgs returns a copy of the given arguments with the values of the
+// given secret flags (e.g. '--password') replaced with '***', for logging the
+// arguments safely.
+//
+// Both the '--password secret' and '--password=secret' forms are redacted. The
+// secret flags are typically the names returned by Command.SecretFlagNames,
+// which keeps them in sync with the flags defined with Secret.
+func RedactedArgs(args []string, secrets []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+
+	for i := 0; i < len(redacted); i++ {
+		if StringInSlice(redacted[i], secrets) {
+			if i+1 < len(redacted) {
+				redacted[i+1] = "***"
+				i++
+			}
+			continue
+		}
+
+		if eq := strings.Index(redacted[i], "="); eq != -1 && StringInSlice(redacted[i][:eq], secrets) {
+			redacted[i] = redacted[i][:eq+1] + "***"
+		}
+	}
+	return redacted
+}
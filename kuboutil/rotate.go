@@ -0,0 +1,144 @@
+package kuboutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// rotateTimestamp is the format of the timestamps in the names of the files
+// written by RotateWriter, which sorts the files by when they were created.
+const rotateTimestamp = "20060102T150405.000000000"
+
+// rotateWriter writes to a file in a directory, starting a new one whenever
+// the current one would exceed a maximum size.
+type rotateWriter struct {
+	dir      string
+	prefix   string
+	maxBytes int64
+	archive  func(path string) error
+
+	mu      sync.Mutex
+	file    *os.File
+	written int64
+
+	// rotateErr is the first error from starting a new file or closing and
+	// archiving the previous one, which is returned by Close
+	rotateErr error
+}
+
+// RotateWriter returns a writer that writes to a new file named
+// '<prefix>-<timestamp>.log' in the given directory (which is created if
+// needed), and an error if the file can't be created.
+//
+// Whenever a write would make the current file exceed maxBytes, the file is
+// closed and the write goes to a new one. A single write larger than maxBytes
+// is written to a file of its own. If the new file can't be created, the write
+// goes to the current file instead.
+//
+// Close must be called to close the current file, and returns the first error
+// from starting a new file or closing the previous one (if any), since they
+// are not returned by the writes.
+func RotateWriter(dir string, prefix string, maxBytes int64) (io.WriteCloser, error) {
+	return RotateWriterArchive(dir, prefix, maxBytes, nil)
+}
+
+// RotateWriterArchive is like RotateWriter, but calls the given function with
+// the path of each file after it is closed (e.g. to compress it).
+//
+// An error returned by the function is returned by Close, since the data is
+// still written (to the new file). The writer keeps writing to the new file
+// regardless.
+func RotateWriterArchive(dir string, prefix string, maxBytes int64, archive func(path string) error) (io.WriteCloser, error) {
+	w := &rotateWriter{
+		dir:      dir,
+		prefix:   prefix,
+		maxBytes: maxBytes,
+		archive:  archive,
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *rotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	// Start a new file if the current one would exceed the maximum size,
+	// unless nothing has been written to it yet. If the new file can't be
+	// created, the current one is kept
+	if w.written > 0 && w.written+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil && w.rotateErr == nil {
+			w.rotateErr = err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// Close closes the current file, archiving it if needed, and returns the first
+// error from rotating the files if there was one.
+func (w *rotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	f := w.file
+	w.file = nil
+	if err := w.closeFile(f); err != nil {
+		return err
+	}
+	return w.rotateErr
+}
+
+// open creates a new file to write to.
+func (w *rotateWriter) open() error {
+	name := fmt.Sprintf("%s-%s.log", w.prefix, time.Now().Format(rotateTimestamp))
+	path := filepath.Join(w.dir, name)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return fileError(path, err)
+	}
+	w.file = f
+	w.written = 0
+	return nil
+}
+
+// rotate creates a new file to write to, and then closes and archives the
+// previous one.
+func (w *rotateWriter) rotate() error {
+	prev := w.file
+	if err := w.open(); err != nil {
+		return err
+	}
+	return w.closeFile(prev)
+}
+
+// closeFile closes the given file and archives it.
+func (w *rotateWriter) closeFile(f *os.File) error {
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if w.archive != nil {
+		return w.archive(f.Name())
+	}
+	return nil
+}
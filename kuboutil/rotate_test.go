@@ -0,0 +1,73 @@
+package kuboutil
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRotateWriter(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := RotateWriter(dir, "test", 10)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, data := range []string{"12345", "67890", "abc", "a very long line\n"} {
+		if n, err := w.Write([]byte(data)); err != nil || n != len(data) {
+			t.Fatalf("expected %d bytes written and no error, got %d and: %v", len(data), n, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "test-*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(paths)
+
+	var contents []string
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(b))
+	}
+	expected := []string{"1234567890", "abc", "a very long line\n"}
+	if strings.Join(contents, "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected files %q, got %q", expected, contents)
+	}
+}
+
+func TestRotateWriterArchiveError(t *testing.T) {
+	archiveErr := errors.New("archive failed")
+	var archived []string
+
+	w, err := RotateWriterArchive(t.TempDir(), "test", 4, func(path string) error {
+		archived = append(archived, path)
+		return archiveErr
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, data := range []string{"abcd", "efgh"} {
+		if n, err := w.Write([]byte(data)); err != nil || n != len(data) {
+			t.Fatalf("expected %d bytes written and no error, got %d and: %v", len(data), n, err)
+		}
+	}
+	if err := w.Close(); err != archiveErr {
+		t.Fatalf("expected error %v, got: %v", archiveErr, err)
+	}
+	if len(archived) != 2 {
+		t.Fatalf("expected 2 files archived, got %d", len(archived))
+	}
+	if _, err := w.Write([]byte("x")); err == nil {
+		t.Fatal("expected an error after closing, got none")
+	}
+}
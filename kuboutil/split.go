@@ -0,0 +1,84 @@
+package kuboutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitArgs splits the given line into arguments using shell-like quoting
+// rules and an error if a quote is not closed.
+//
+// Double quoted strings may contain escaped double quotes and backslashes,
+// single quoted strings are taken as is, and a backslash outside of quotes
+// escapes the next character (e.g. 'escape\ space').
+func SplitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		arg     strings.Builder
+		inArg   bool
+		escaped bool
+		quote   rune
+	)
+
+	for _, r := range line {
+		if escaped {
+			// Within double quotes, only quotes and backslashes are escaped
+			if quote == '"' && r != '"' && r != '\\' {
+				arg.WriteRune('\\')
+			}
+			arg.WriteRune(r)
+			escaped = false
+			continue
+		}
+
+		switch quote {
+		case '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+			continue
+		case '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				arg.WriteRune(r)
+			}
+			continue
+		}
+
+		switch r {
+		case ' ', '\t', '\n', '\r':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		case '\'', '"':
+			quote = r
+			inArg = true
+		case '\\':
+			escaped = true
+			inArg = true
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unclosed quote in: %s", line)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in: %s", line)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args, nil
+}
@@ -0,0 +1,46 @@
+package kuboutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+		valid    bool
+	}{
+		{"", nil, true},
+		{"  ", nil, true},
+		{"a b  c", []string{"a", "b", "c"}, true},
+		{"a\tb\nc", []string{"a", "b", "c"}, true},
+		{`"a b" c`, []string{"a b", "c"}, true},
+		{`'a "b"' c`, []string{`a "b"`, "c"}, true},
+		{`'a\b'`, []string{`a\b`}, true},
+		{`"a \"b\" \\ \c"`, []string{`a "b" \ \c`}, true},
+		{`escape\ space`, []string{"escape space"}, true},
+		{`""`, []string{""}, true},
+		{`a''b`, []string{"ab"}, true},
+		{`"a`, nil, false},
+		{`'a`, nil, false},
+		{`a\`, nil, false},
+	}
+
+	for _, test := range tests {
+		actual, err := SplitArgs(test.line)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("SplitArgs(%q): expected an error, got none", test.line)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("SplitArgs(%q): expected no error, got: %v", test.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("SplitArgs(%q): expected %q, got %q", test.line, test.expected, actual)
+		}
+	}
+}
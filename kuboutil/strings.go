@@ -0,0 +1,76 @@
+package kuboutil
+
+import "strings"
+
+// StringSliceDedupe returns a copy of the given slice with duplicates removed,
+// keeping the first occurrence of each string.
+//
+// If caseSensitive is false, strings that only differ in case are treated as
+// duplicates (e.g. 'Go' and 'go'), keeping the casing first seen.
+func StringSliceDedupe(ss []string, caseSensitive bool) []string {
+	seen := make(map[string]bool)
+
+	var deduped []string
+	for _, s := range ss {
+		key := s
+		if !caseSensitive {
+			key = strings.ToLower(s)
+		}
+
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, s)
+	}
+	return deduped
+}
+
+// UniqueStrings returns a copy of the given slice with duplicates removed,
+// keeping the order of the first occurrences.
+func UniqueStrings(ss []string) []string {
+	return StringSliceDedupe(ss, true)
+}
+
+// StringInSlice returns whether the given string is in the given slice.
+func StringInSlice(s string, slice []string) bool {
+	for _, elem := range slice {
+		if elem == s {
+			return true
+		}
+	}
+	return false
+}
+
+// StringInSliceFold is like StringInSlice, but compares the strings
+// case-insensitively.
+func StringInSliceFold(s string, slice []string) bool {
+	for _, elem := range slice {
+		if strings.EqualFold(elem, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSlice splits the given string by the given separator, trimming the
+// whitespace around each element and dropping empty elements (e.g. 'a, b ,,c'
+// becomes ['a', 'b', 'c']).
+func ParseSlice(s, sep string) []string {
+	var elems []string
+	for _, elem := range ParseSliceKeepEmpty(s, sep) {
+		if elem != "" {
+			elems = append(elems, elem)
+		}
+	}
+	return elems
+}
+
+// ParseSliceKeepEmpty is like ParseSlice, but keeps empty elements.
+func ParseSliceKeepEmpty(s, sep string) []string {
+	elems := strings.Split(s, sep)
+	for i, elem := range elems {
+		elems[i] = strings.TrimSpace(elem)
+	}
+	return elems
+}
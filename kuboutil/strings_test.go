@@ -0,0 +1,53 @@
+package kuboutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSlice(t *testing.T) {
+	tests := []struct {
+		s        string
+		sep      string
+		expected []string
+	}{
+		{"", ",", nil},
+		{"a", ",", []string{"a"}},
+		{"a,b,c", ",", []string{"a", "b", "c"}},
+		{"a, b ,c", ",", []string{"a", "b", "c"}},
+		{" a ,, ,c ", ",", []string{"a", "c"}},
+		{",,", ",", nil},
+		{"a; b;c", ";", []string{"a", "b", "c"}},
+		{"a::b", "::", []string{"a", "b"}},
+		{"a b", ",", []string{"a b"}},
+	}
+
+	for _, test := range tests {
+		actual := ParseSlice(test.s, test.sep)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("ParseSlice(%q, %q): expected %q, got %q", test.s, test.sep, test.expected, actual)
+		}
+	}
+}
+
+func TestParseSliceKeepEmpty(t *testing.T) {
+	tests := []struct {
+		s        string
+		sep      string
+		expected []string
+	}{
+		{"", ",", []string{""}},
+		{"a", ",", []string{"a"}},
+		{"a, b ,c", ",", []string{"a", "b", "c"}},
+		{" a ,, ,c ", ",", []string{"a", "", "", "c"}},
+		{",,", ",", []string{"", "", ""}},
+		{"a::b", "::", []string{"a", "b"}},
+	}
+
+	for _, test := range tests {
+		actual := ParseSliceKeepEmpty(test.s, test.sep)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("ParseSliceKeepEmpty(%q, %q): expected %q, got %q", test.s, test.sep, test.expected, actual)
+		}
+	}
+}
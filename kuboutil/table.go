@@ -0,0 +1,113 @@
+package kuboutil
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// table holds the header and rows of a table, which are written out by the
+// table formats embedding it (e.g. MarkdownTable).
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// Header sets the column names of the table.
+func (t *table) Header(cols ...string) {
+	t.header = cols
+}
+
+// Add adds a row to the table.
+func (t *table) Add(row ...string) {
+	t.rows = append(t.rows, row)
+}
+
+// columns returns the number of columns in the table.
+func (t *table) columns() int {
+	n := len(t.header)
+	for _, row := range t.rows {
+		if len(row) > n {
+			n = len(row)
+		}
+	}
+	return n
+}
+
+// cells returns the header followed by the rows, with each cell formatted
+// using the given function and missing cells filled in with empty strings.
+func (t *table) cells(format func(string) string) [][]string {
+	n := t.columns()
+
+	var cells [][]string
+	for _, row := range append([][]string{t.header}, t.rows...) {
+		formatted := make([]string, n)
+		for i := range formatted {
+			if i < len(row) {
+				formatted[i] = format(row[i])
+			}
+		}
+		cells = append(cells, formatted)
+	}
+	return cells
+}
+
+// widths returns the width of each column in the given cells in runes, which
+// should be at least the given minimum.
+func widths(cells [][]string, minWidth int) []int {
+	widths := make([]int, len(cells[0]))
+	for i := range widths {
+		widths[i] = minWidth
+		for _, row := range cells {
+			if width := utf8.RuneCountInString(row[i]); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+	return widths
+}
+
+// MarkdownTable writes a table in the GitHub/GitLab Markdown table format.
+type MarkdownTable struct {
+	table
+	w io.Writer
+}
+
+// NewMarkdownTable creates a new Markdown table that writes to the given
+// writer on Flush.
+func NewMarkdownTable(w io.Writer) *MarkdownTable {
+	return &MarkdownTable{w: w}
+}
+
+// Flush writes the table to the writer and an error if it could not be
+// written.
+//
+// Pipe characters within cells are escaped.
+func (t *MarkdownTable) Flush() error {
+	cells := t.cells(func(cell string) string {
+		return strings.Replace(cell, "|", "\\|", -1)
+	})
+	widths := widths(cells, 3)
+
+	var markdown strings.Builder
+	for i, row := range cells {
+		markdown.WriteString("|")
+		for j, cell := range row {
+			markdown.WriteString(fmt.Sprintf(" %-*s |", widths[j], cell))
+		}
+		markdown.WriteString("\n")
+
+		// Write the delimiter row after the header
+		if i == 0 {
+			markdown.WriteString("|")
+			for _, width := range widths {
+				markdown.WriteString(fmt.Sprintf("%s|", strings.Repeat("-", width+2)))
+			}
+			markdown.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(t.w, markdown.String())
+	return err
+}
@@ -0,0 +1,59 @@
+package kuboutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// defaultTerminalWidth is the width used when the terminal width can't be
+// determined.
+const defaultTerminalWidth = 80
+
+// TerminalSize returns the width and height of the terminal connected to
+// stdout and an error if it can't be determined.
+func TerminalSize() (width, height int, err error) {
+	return TerminalSizeOf(os.Stdout)
+}
+
+// TerminalSizeOf returns the width and height of the terminal connected to the
+// given writer and an error if it can't be determined (e.g. if the writer is
+// not a terminal).
+func TerminalSizeOf(w io.Writer) (width, height int, err error) {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return 0, 0, fmt.Errorf("writer is not a terminal")
+	}
+	return terminalSize(f.Fd())
+}
+
+// TerminalWidth returns the width of the terminal connected to stdout, falling
+// back to 80 if it can't be determined.
+func TerminalWidth() int {
+	return TerminalWidthOf(os.Stdout)
+}
+
+// TerminalWidthOf returns the width of the terminal connected to the given
+// writer, falling back to 80 if it can't be determined.
+func TerminalWidthOf(w io.Writer) int {
+	width, _, err := TerminalSizeOf(w)
+	if err != nil || width <= 0 {
+		return defaultTerminalWidth
+	}
+	return width
+}
+
+// IsTTY returns whether the given file is a terminal.
+func IsTTY(f *os.File) bool {
+	return isTerminal(f.Fd())
+}
+
+// StdinIsTTY returns whether stdin is a terminal.
+func StdinIsTTY() bool {
+	return IsTTY(os.Stdin)
+}
+
+// StdoutIsTTY returns whether stdout is a terminal.
+func StdoutIsTTY() bool {
+	return IsTTY(os.Stdout)
+}
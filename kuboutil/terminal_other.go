@@ -0,0 +1,18 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package kuboutil
+
+import "fmt"
+
+// terminalSize returns an error since the terminal size can't be determined
+// on this platform.
+func terminalSize(fd uintptr) (int, int, error) {
+	return 0, 0, fmt.Errorf("could not get terminal size: unsupported platform")
+}
+
+// isTerminal returns false since terminals can't be detected on this
+// platform.
+func isTerminal(fd uintptr) bool {
+	return false
+}
@@ -0,0 +1,32 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package kuboutil
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// winsize is the struct filled in by the TIOCGWINSZ ioctl.
+type winsize struct {
+	rows, cols, xpixel, ypixel uint16
+}
+
+// terminalSize returns the size of the terminal with the given file
+// descriptor.
+func terminalSize(fd uintptr) (int, int, error) {
+	var ws winsize
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0, 0, fmt.Errorf("could not get terminal size: %v", errno)
+	}
+	return int(ws.cols), int(ws.rows), nil
+}
+
+// isTerminal returns whether the given file descriptor is a terminal.
+func isTerminal(fd uintptr) bool {
+	_, _, err := terminalSize(fd)
+	return err == nil
+}
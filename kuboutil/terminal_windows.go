@@ -0,0 +1,48 @@
+//go:build windows
+// +build windows
+
+package kuboutil
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var getConsoleScreenBufferInfo = syscall.NewLazyDLL("kernel32.dll").NewProc("GetConsoleScreenBufferInfo")
+
+type coord struct {
+	x, y int16
+}
+
+type smallRect struct {
+	left, top, right, bottom int16
+}
+
+// consoleScreenBufferInfo is the struct filled in by
+// GetConsoleScreenBufferInfo.
+type consoleScreenBufferInfo struct {
+	size              coord
+	cursorPosition    coord
+	attributes        uint16
+	window            smallRect
+	maximumWindowSize coord
+}
+
+// terminalSize returns the size of the console with the given handle.
+func terminalSize(fd uintptr) (int, int, error) {
+	var info consoleScreenBufferInfo
+	ok, _, err := getConsoleScreenBufferInfo.Call(fd, uintptr(unsafe.Pointer(&info)))
+	if ok == 0 {
+		return 0, 0, fmt.Errorf("could not get terminal size: %v", err)
+	}
+	width := int(info.window.right-info.window.left) + 1
+	height := int(info.window.bottom-info.window.top) + 1
+	return width, height, nil
+}
+
+// isTerminal returns whether the given handle is a console.
+func isTerminal(fd uintptr) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(fd), &mode) == nil
+}
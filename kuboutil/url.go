@@ -0,0 +1,45 @@
+package kuboutil
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// NormalizeURL returns the given URL in its canonical form, with 'https://'
+// added if it has no scheme and the trailing slashes removed (e.g.
+// 'example.com/api/' becomes 'https://example.com/api'), and an error if it is
+// not a valid URL.
+func NormalizeURL(raw string) (string, error) {
+	withScheme := raw
+	if !strings.Contains(raw, "://") {
+		withScheme = fmt.Sprintf("https://%s", raw)
+	}
+
+	u, err := url.Parse(withScheme)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid url: %s", raw)
+	}
+
+	// Trim the escaped path, so that escaped slashes are kept as is
+	path := strings.TrimRight(u.EscapedPath(), "/")
+	u.Path, err = url.PathUnescape(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %s", raw)
+	}
+	u.RawPath = path
+
+	return u.String(), nil
+}
+
+// ValidateURL returns an error if the given URL is not valid or has no scheme
+// or host, without modifying it.
+//
+// It can be used as the Validator of a flag.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid url: %s", raw)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package kuboutil
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected string
+		valid    bool
+	}{
+		{"example.com", "https://example.com", true},
+		{"example.com/api/", "https://example.com/api", true},
+		{"http://example.com//", "http://example.com", true},
+		{"https://example.com/a%2Fb/", "https://example.com/a%2Fb", true},
+		{"https://example.com/api?q=1", "https://example.com/api?q=1", true},
+		{"localhost:8080/", "https://localhost:8080", true},
+		{"", "", false},
+		{"https://", "", false},
+		{"http://exa mple.com", "", false},
+	}
+
+	for _, test := range tests {
+		actual, err := NormalizeURL(test.raw)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("NormalizeURL(%q): expected an error, got none", test.raw)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NormalizeURL(%q): expected no error, got: %v", test.raw, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("NormalizeURL(%q): expected %q, got %q", test.raw, test.expected, actual)
+		}
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		raw   string
+		valid bool
+	}{
+		{"https://example.com", true},
+		{"http://localhost:8080/api", true},
+		{"example.com", false},
+		{"https://", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		err := ValidateURL(test.raw)
+		if test.valid && err != nil {
+			t.Errorf("ValidateURL(%q): expected no error, got: %v", test.raw, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("ValidateURL(%q): expected an error, got none", test.raw)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package kuboutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IsInt returns an error if the given value is not an int.
+//
+// Like the other validators, it can be used as the Validator of a flag or an
+// argument.
+func IsInt(v string) error {
+	if _, err := strconv.Atoi(v); err != nil {
+		return fmt.Errorf("%s is not an int", v)
+	}
+	return nil
+}
+
+// IsFloat returns an error if the given value is not a float.
+func IsFloat(v string) error {
+	if _, err := strconv.ParseFloat(v, 64); err != nil {
+		return fmt.Errorf("%s is not a float", v)
+	}
+	return nil
+}
+
+// IsURL returns an error if the given value is not a URL with a scheme and a
+// host, as by ValidateURL.
+func IsURL(v string) error {
+	return ValidateURL(v)
+}
+
+// OneOf returns a validator that returns an error if the value is not one of
+// the given values.
+func OneOf(vals ...string) func(string) error {
+	return func(v string) error {
+		if !StringInSlice(v, vals) {
+			return fmt.Errorf("%s is not one of [%s]", v, strings.Join(vals, ", "))
+		}
+		return nil
+	}
+}
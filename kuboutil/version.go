@@ -0,0 +1,82 @@
+package kuboutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseVersion parses the given semantic version (e.g. 'v1.2.3') and returns
+// its major, minor and patch numbers and an error if it can't be parsed.
+//
+// The leading 'v' is optional, as are the minor and patch numbers (e.g. 'v1'
+// is the same as 'v1.0.0'). Any pre-release or build metadata (e.g. '-rc.1'
+// or '+build') is ignored.
+func ParseVersion(s string) (major, minor, patch int, err error) {
+	numbers, _ := splitVersion(s)
+
+	parts := strings.Split(numbers, ".")
+	if len(parts) > 3 {
+		return 0, 0, 0, fmt.Errorf("could not parse version %s", s)
+	}
+
+	var parsed [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, 0, 0, fmt.Errorf("could not parse version %s", s)
+		}
+		parsed[i] = n
+	}
+
+	return parsed[0], parsed[1], parsed[2], nil
+}
+
+// CompareVersions compares the given semantic versions and returns -1 if a is
+// older than b, 1 if a is newer than b and 0 if they are the same.
+//
+// A pre-release version is older than the same version without a pre-release.
+// If either version can't be parsed (see ParseVersion), they are compared as
+// strings instead.
+func CompareVersions(a, b string) int {
+	aMajor, aMinor, aPatch, aErr := ParseVersion(a)
+	bMajor, bMinor, bPatch, bErr := ParseVersion(b)
+	if aErr != nil || bErr != nil {
+		return strings.Compare(a, b)
+	}
+
+	for _, pair := range [][2]int{{aMajor, bMajor}, {aMinor, bMinor}, {aPatch, bPatch}} {
+		if pair[0] < pair[1] {
+			return -1
+		}
+		if pair[0] > pair[1] {
+			return 1
+		}
+	}
+
+	_, aPre := splitVersion(a)
+	_, bPre := splitVersion(b)
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	default:
+		return strings.Compare(aPre, bPre)
+	}
+}
+
+// splitVersion splits the given version into its numbers (without the leading
+// 'v') and its pre-release, dropping any build metadata.
+func splitVersion(s string) (numbers, pre string) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, "-"); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
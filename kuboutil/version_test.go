@@ -0,0 +1,64 @@
+package kuboutil
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		s                   string
+		major, minor, patch int
+		valid               bool
+	}{
+		{"1.2.3", 1, 2, 3, true},
+		{"v1.2.3", 1, 2, 3, true},
+		{"v1", 1, 0, 0, true},
+		{"1.2", 1, 2, 0, true},
+		{"1.2.3-rc.1", 1, 2, 3, true},
+		{"1.2.3+build.5", 1, 2, 3, true},
+		{"1.2.3.4", 0, 0, 0, false},
+		{"1.x", 0, 0, 0, false},
+		{"", 0, 0, 0, false},
+	}
+
+	for _, test := range tests {
+		major, minor, patch, err := ParseVersion(test.s)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("ParseVersion(%q): expected an error, got none", test.s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseVersion(%q): expected no error, got: %v", test.s, err)
+			continue
+		}
+		if major != test.major || minor != test.minor || patch != test.patch {
+			t.Errorf("ParseVersion(%q): expected %d.%d.%d, got %d.%d.%d", test.s, test.major, test.minor, test.patch, major, minor, patch)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1", "1.0.0", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.2.3-rc.1", "1.2.3", -1},
+		{"1.2.3", "1.2.3-rc.1", 1},
+		{"1.2.3-alpha", "1.2.3-beta", -1},
+		{"1.2.3+a", "1.2.3+b", 0},
+		{"abc", "abd", -1},
+	}
+
+	for _, test := range tests {
+		actual := CompareVersions(test.a, test.b)
+		if actual != test.expected {
+			t.Errorf("CompareVersions(%q, %q): expected %d, got %d", test.a, test.b, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package kuboutil
+
+import "sync"
+
+// WaitGroup is a sync.WaitGroup that collects the errors returned by the
+// functions it runs.
+//
+// The zero value is ready to use.
+type WaitGroup struct {
+	sync.WaitGroup
+
+	mu   sync.Mutex
+	errs MultiError
+}
+
+// Go calls the given function in a new goroutine, collecting the error it
+// returns if it is not nil.
+func (wg *WaitGroup) Go(fn func() error) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := fn(); err != nil {
+			wg.mu.Lock()
+			wg.errs = append(wg.errs, err)
+			wg.mu.Unlock()
+		}
+	}()
+}
+
+// Wait waits for all the functions to return, and returns the errors collected
+// (in the order they were returned) as a MultiError, or nil if there are none.
+func (wg *WaitGroup) Wait() error {
+	wg.WaitGroup.Wait()
+
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	if len(wg.errs) == 0 {
+		return nil
+	}
+	return append(MultiError{}, wg.errs...)
+}
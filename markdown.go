@@ -0,0 +1,114 @@
+package kubo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ravernkoh/kubo/kuboutil"
+)
+
+// GenerateMarkdownTree writes a Markdown page for each command that is not
+// hidden to the given directory (which is created if it doesn't exist), and an
+// error if any of them could not be written.
+//
+// The page of the root command is named after it (e.g. 'tool.md'), while the
+// pages of the other commands are named after their full names joined by
+// underscores (e.g. 'tool_remote_add.md'). Each page has the descriptions,
+// synopsis and flags of the command, with links to the pages of its parent,
+// siblings and children.
+func (a *App) GenerateMarkdownTree(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return writeMarkdownPage(dir, a.Root)
+}
+
+// writeMarkdownPage writes the page for the given command to the given
+// directory, recursing into each child that is not hidden.
+func writeMarkdownPage(dir string, cmd *Command) error {
+	var page strings.Builder
+
+	// Title and description
+	page.WriteString(fmt.Sprintf("# %s\n\n", cmd.FullName()))
+	if description := cmd.HelpDescription(); description != "" {
+		page.WriteString(fmt.Sprintf("%s\n\n", description))
+	}
+	if cmd.LongDescription != "" {
+		page.WriteString(fmt.Sprintf("%s\n\n", strings.TrimSpace(cmd.LongDescription)))
+	}
+
+	// Synopsis
+	page.WriteString("## Synopsis\n\n")
+	page.WriteString("```\n")
+	argumentsUsage := cmd.ArgumentsUsage()
+	if argumentsUsage != "" {
+		page.WriteString(fmt.Sprintf("%s %s\n", cmd.FullName(), argumentsUsage))
+	}
+	if len(cmd.Children()) > 0 {
+		page.WriteString(fmt.Sprintf("%s <command>\n", cmd.FullName()))
+	} else if argumentsUsage == "" {
+		page.WriteString(fmt.Sprintf("%s\n", cmd.FullName()))
+	}
+	page.WriteString("```\n")
+
+	// Flags
+	if flags := visibleFlags(cmd.allFlags()); len(flags) > 0 {
+		page.WriteString("\n## Flags\n\n")
+		table := kuboutil.NewMarkdownTable(&page)
+		table.Header("Flag", "Description")
+		for _, flag := range flags {
+			table.Add(fmt.Sprintf("`%s`", flag.Usage()), flag.HelpDescription())
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+
+	// Links to the parent, siblings and children
+	var links []string
+	if cmd.parent != nil {
+		links = append(links, markdownLink(cmd.parent))
+		for _, sibling := range cmd.parent.Children() {
+			if sibling != cmd {
+				links = append(links, markdownLink(sibling))
+			}
+		}
+	}
+	for _, child := range cmd.Children() {
+		links = append(links, markdownLink(child))
+	}
+	if len(links) > 0 {
+		page.WriteString("\n## See also\n\n")
+		for _, link := range links {
+			page.WriteString(fmt.Sprintf("- %s\n", link))
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, markdownFileName(cmd)), []byte(page.String()), 0644); err != nil {
+		return err
+	}
+
+	for _, child := range cmd.Children() {
+		if err := writeMarkdownPage(dir, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownFileName returns the name of the Markdown page of the given command.
+func markdownFileName(cmd *Command) string {
+	return fmt.Sprintf("%s.md", strings.Replace(cmd.FullName(), " ", "_", -1))
+}
+
+// markdownLink returns a Markdown link to the page of the given command,
+// followed by its description.
+func markdownLink(cmd *Command) string {
+	link := fmt.Sprintf("[%s](%s)", cmd.FullName(), markdownFileName(cmd))
+	if short := cmd.ShortDescription(); short != "" {
+		link = fmt.Sprintf("%s - %s", link, short)
+	}
+	return link
+}
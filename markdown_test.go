@@ -0,0 +1,46 @@
+package kubo_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ravernkoh/kubo"
+)
+
+func TestGenerateMarkdownTree(t *testing.T) {
+	root := &kubo.Command{Name: "tool", Description: "the tool"}
+	remote := &kubo.Command{Name: "remote", Description: "manages remotes"}
+	remote.Add(&kubo.Command{Name: "add", Description: "adds a remote", Arguments: []kubo.Argument{{Name: "url"}}})
+	root.Add(remote)
+	root.Add(&kubo.Command{Name: "secret", Hidden: true})
+
+	dir := filepath.Join(t.TempDir(), "docs", "cli")
+	if err := kubo.NewApp(root).GenerateMarkdownTree(dir); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	tests := []struct {
+		file     string
+		contains string
+	}{
+		{"tool.md", "# tool\n\nthe tool\n"},
+		{"tool_remote.md", "```\ntool remote <command>\n```\n"},
+		{"tool_remote_add.md", "```\ntool remote add <url>\n```\n"},
+	}
+
+	for _, test := range tests {
+		page, err := os.ReadFile(filepath.Join(dir, test.file))
+		if err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.file, err)
+			continue
+		}
+		if !strings.Contains(string(page), test.contains) {
+			t.Errorf("%s: expected %q in:\n%s", test.file, test.contains, page)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tool_secret.md")); !os.IsNotExist(err) {
+		t.Errorf("expected no page for the hidden command, got: %v", err)
+	}
+}
@@ -0,0 +1,462 @@
+package kubo
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ravernkoh/kubo/kuboutil"
+)
+
+// ParseResult represents the result of parsing raw arguments.
+type ParseResult struct {
+	Command *Command // command that would be run
+
+	Flags     map[string]string
+	Arguments map[string][]string // multiple arguments have all their values
+
+	// RemainingArgs are the extra arguments supplied after all the arguments
+	// have been parsed, which is only allowed if the command has a
+	// TrailingArgValidator.
+	RemainingArgs []string
+
+	// PassthroughArgs are the arguments supplied after the '--' separator,
+	// which are parsed as arguments even if they look like flags.
+	PassthroughArgs []string
+
+	// Help is whether the default help flag was passed, in which case the
+	// arguments are not parsed.
+	Help bool
+}
+
+// Parse parses the given arguments against the command tree starting from the
+// given root command and an error if they can't be parsed.
+//
+// Like App.Run, the first argument is the program name (e.g. os.Args), so an
+// error is returned if there are no arguments at all.
+//
+// Nothing is run, including the TrailingArgValidator of the command, and the
+// root command is left as is, even if it belongs to an app. The default flags
+// and child commands of an app (e.g. '--version') are not parsed.
+func Parse(root *Command, args []string) (*ParseResult, error) {
+	if err := root.validateFlags(); err != nil {
+		return nil, err
+	}
+
+	// Parse with a detached app, which is never attached to the root
+	// command unlike one created with NewApp
+	a := &App{
+		Root:             root,
+		FlagInterspersed: true,
+	}
+	cmd, ctx, err := a.parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	arguments := make(map[string][]string)
+	for name, value := range ctx.arguments {
+		arguments[name] = []string{value}
+	}
+	if ctx.argumentMultipleValue != nil {
+		arguments[ctx.argumentMultipleName] = ctx.argumentMultipleValue
+	}
+
+	return &ParseResult{
+		Command:         cmd,
+		Flags:           ctx.flags,
+		Arguments:       arguments,
+		RemainingArgs:   ctx.args,
+		PassthroughArgs: ctx.passthroughArgs,
+		Help:            ctx.help,
+	}, nil
+}
+
+// parse parses the given arguments, returning the command to run and the
+// context to run it with.
+//
+// The arguments are parsed in order. The flags of each command are parsed
+// until the first argument that is not one of its flags, which is then looked
+// up as a child command. Once no child command is found, the rest of the
+// arguments are parsed as the flags and arguments of the last command found.
+// A flag passed after a child command is parsed as the flag of the child
+// command, even if it was also defined by the parent, while a flag passed
+// before it returns an error unless the child command inherits it. The
+// arguments after the first '--' are only parsed as arguments, in order.
+//
+// If a child command is not found, the command and context are returned along
+// with the command not found error.
+func (a *App) parse(args []string) (*Command, *Context, error) {
+	// warnings are the deprecation warnings for the commands and flags
+	// used, which are collected across all the commands parsed
+	var warnings []string
+	warn := func(warning string) {
+		for _, w := range warnings {
+			if w == warning {
+				return
+			}
+		}
+		warnings = append(warnings, warning)
+	}
+
+	// Create the context to pass to the command
+	ctx := Context{
+		arguments:     make(map[string]string),
+		flags:         make(map[string]string),
+		flagsMultiple: make(map[string][]string),
+		flagsPassed:   make(map[string]bool),
+		stdin:         a.Stdin,
+		stdout:        a.Stdout,
+		stderr:        a.Stderr,
+		app:           a,
+	}
+
+	if len(args) == 0 {
+		return nil, nil, fmt.Errorf("no arguments supplied: expected at least the program name")
+	}
+
+	cmd := a.Root
+	tmpArgs := args[1:]
+	for {
+		if cmd.Arguments != nil && cmd.PositionalArgs != nil {
+			return nil, nil, fmt.Errorf("command %s: only one of arguments and positional args can be set", cmd.Name)
+		}
+		if err := cmd.validateArguments(); err != nil {
+			return nil, nil, err
+		}
+		if cmd.Deprecated != "" {
+			warn(fmt.Sprintf("command %s is deprecated: %s", cmd.FullName(), cmd.Deprecated))
+		}
+
+		// Parse the flags of the command until the first argument that
+		// is not one of them
+		for len(tmpArgs) > 0 {
+			if _, ok := parseFlagName(tmpArgs[0]); !ok {
+				break
+			}
+			n, err := a.parseFlag(cmd, &ctx, tmpArgs, warn)
+			if _, ok := err.(*flagNotFoundError); ok {
+				break
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			tmpArgs = tmpArgs[n:]
+		}
+
+		// Parse the argument as a child command
+		if len(tmpArgs) == 0 || tmpArgs[0] == "--" {
+			break
+		}
+		child, err := a.command(cmd, tmpArgs[0])
+		if _, ok := err.(*commandNotFoundError); ok {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Only the flags inherited by the child command (or accepted by
+		// every command) can be passed before it, since the other ones
+		// would not be seen by the command run, and those it redefines
+		// would be mistaken for its own flags
+		inherited := make(map[string]bool)
+		for _, flag := range append(child.inheritedFlags(), a.DefaultFlags(child)...) {
+			inherited[flag.Name] = true
+		}
+		var names []string
+		for name := range ctx.flags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if inherited[name] {
+				continue
+			}
+			if _, err := child.flag(name); err == nil {
+				return nil, nil, fmt.Errorf("flag --%s is redefined by %s and must be passed after it", name, child.FullName())
+			}
+			return nil, nil, fmt.Errorf("flag --%s is not defined for %s", name, child.FullName())
+		}
+
+		cmd = child
+		tmpArgs = tmpArgs[1:]
+	}
+
+	ctx.command = cmd
+	if cmd.out != nil {
+		ctx.stdout = cmd.out
+	}
+	if cmd.err != nil {
+		ctx.stderr = cmd.err
+	}
+
+	// Split off the arguments after the separator, which are not parsed as
+	// flags
+	for i, arg := range tmpArgs {
+		if arg == "--" {
+			ctx.passthroughArgs = append([]string{}, tmpArgs[i+1:]...)
+			tmpArgs = tmpArgs[:i]
+			break
+		}
+	}
+
+	// Parse the rest of the flags, leaving the arguments
+	var rawArgs []string
+	for i := 0; i < len(tmpArgs); i++ {
+		if _, ok := parseFlagName(tmpArgs[i]); !ok {
+			if !a.FlagInterspersed {
+				// Stop parsing flags at the first argument, so that
+				// the rest are parsed as arguments
+				rawArgs = append(rawArgs, tmpArgs[i:]...)
+				break
+			}
+			rawArgs = append(rawArgs, tmpArgs[i])
+			continue
+		}
+
+		n, err := a.parseFlag(cmd, &ctx, tmpArgs[i:], warn)
+		if err != nil {
+			return nil, nil, err
+		}
+		i += n - 1 // skip the value of the flag
+	}
+
+	// requiredErr is used to hold the error for the first required flag
+	// not passed, which is only returned after the arguments are parsed
+	var requiredErr error
+
+	// Set all flags that were not passed to the values of their environment
+	// variables or their default values, and all flags with Bool to false if
+	// not set to true
+	flags := cmd.allFlags()
+	for _, flag := range a.DefaultFlags(cmd) {
+		// The default bool flags (e.g. version) are handled by the app
+		// instead of being set
+		if !flag.Bool {
+			flags = append(flags, flag)
+		}
+	}
+	for _, flag := range flags {
+		if _, err := ctx.Flag(flag.Name); err == nil {
+			continue
+		}
+
+		if value, ok := os.LookupEnv(flag.EnvVar); ok && flag.EnvVar != "" {
+			if a.ExpandEnvInFlags {
+				value = os.ExpandEnv(value)
+			}
+
+			// Split the value into the values of a multiple flag
+			values := []string{value}
+			split := flag.Multiple && flag.EnvVarSeparator != ""
+			if split {
+				values = kuboutil.ParseSlice(value, flag.EnvVarSeparator)
+			}
+			for _, value := range values {
+				if err := flag.checkAllowedValue(value); err != nil {
+					return nil, nil, fmt.Errorf("flag --%s from %s: %s", flag.Name, flag.EnvVar, err)
+				}
+				if err := flag.validate(value); err != nil {
+					return nil, nil, fmt.Errorf("invalid value for flag %s from %s: %s", flag.Name, flag.EnvVar, err)
+				}
+			}
+			if split {
+				ctx.flagsMultiple[flag.Name] = values
+				value = ""
+				if len(values) > 0 {
+					value = values[len(values)-1]
+				}
+			}
+			ctx.flags[flag.Name] = value
+			continue
+		}
+
+		if flag.Required && requiredErr == nil {
+			requiredErr = fmt.Errorf("flag %s is required", flag.nameAndAliases())
+		}
+
+		if flag.Default != "" {
+			ctx.flags[flag.Name] = flag.Default
+		} else if flag.Bool {
+			ctx.flags[flag.Name] = "false"
+		}
+	}
+
+	// If the first argument is not a child command and it is not possibly
+	// an argument or an extra argument, then return the command not found
+	// error
+	arguments := cmd.arguments()
+	if len(rawArgs) > 0 && len(arguments) == 0 && cmd.TrailingArgValidator == nil {
+		return cmd, &ctx, &commandNotFoundError{
+			name:        rawArgs[0],
+			suggestions: cmd.SubCommandSuggestions(rawArgs[0]),
+		}
+	}
+	if len(ctx.passthroughArgs) > 0 && len(arguments) == 0 && cmd.TrailingArgValidator == nil {
+		return nil, nil, fmt.Errorf("arguments supplied after -- but command %s has no arguments", cmd.FullName())
+	}
+	rawArgs = append(rawArgs, ctx.passthroughArgs...)
+
+	// Skip parsing the arguments if the default help, version or DOT graph
+	// flag was passed, since the command will not be run
+	if ctx.help || ctx.version || ctx.dumpDot {
+		ctx.warnings = warnings
+		return cmd, &ctx, nil
+	}
+
+	// Parse raw arguments as arguments
+	ctx.nargs = len(rawArgs)
+	for _, arg := range arguments {
+		if len(rawArgs) == 0 {
+			if !arg.Optional {
+				return nil, nil, fmt.Errorf("argument not found: %s", arg.Name)
+			}
+
+			// Fall back to the default value of the omitted optional
+			// argument, which is empty if there is none
+			if arg.Multiple {
+				ctx.argumentMultipleName = arg.Name
+				ctx.argumentMultipleValue = []string{}
+				if arg.Default != "" {
+					ctx.argumentMultipleValue = []string{arg.Default}
+				}
+				break
+			}
+			ctx.arguments[arg.Name] = arg.Default
+			continue
+		}
+
+		if arg.Validator != nil {
+			values := rawArgs[:1]
+			if arg.Multiple {
+				values = rawArgs
+			}
+			for _, value := range values {
+				if err := arg.Validator(value); err != nil {
+					return nil, nil, fmt.Errorf("invalid value for argument %s: %s", arg.Name, err)
+				}
+			}
+		}
+
+		if arg.Multiple {
+			ctx.argumentMultipleName = arg.Name
+			ctx.argumentMultipleValue = rawArgs
+			rawArgs = nil
+			break
+		}
+
+		ctx.arguments[arg.Name] = rawArgs[0]
+		rawArgs = rawArgs[1:]
+	}
+	if len(rawArgs) > 0 {
+		if cmd.TrailingArgValidator == nil {
+			return nil, nil, fmt.Errorf("extra arguments supplied")
+		}
+		ctx.args = rawArgs
+	}
+
+	if requiredErr != nil {
+		return nil, nil, requiredErr
+	}
+
+	ctx.warnings = warnings
+	return cmd, &ctx, nil
+}
+
+// parseFlag parses the flag at the start of the given arguments as a flag of
+// the given command, setting its value in the given context, and returns the
+// number of arguments used.
+//
+// If the flag is not defined, a flag not found error is returned.
+func (a *App) parseFlag(cmd *Command, ctx *Context, args []string, warn func(string)) (int, error) {
+	name, _ := parseFlagName(args[0])
+
+	// Try to find the flag definition
+	flag, err := cmd.flag(name)
+	negated := false
+	if err != nil && strings.HasPrefix(name, "no-") {
+		// Try to find the bool flag being negated
+		if negatedFlag, negatedErr := cmd.flag(strings.TrimPrefix(name, "no-")); negatedErr == nil && negatedFlag.Bool && negatedFlag.AllowNegation {
+			flag, err = negatedFlag, nil
+			negated = true
+		}
+	}
+	if err != nil && isHelpFlag(name) && !a.DisableDefaultHelpFlag {
+		// Parse the default help flag as a bool flag without setting it
+		// in the context
+		ctx.help = true
+		return 1, nil
+	}
+	if err != nil && name == "dump-dot" && cmd == a.Root {
+		// Parse the hidden default flag for printing the command tree as a
+		// DOT graph like the default help flag
+		ctx.dumpDot = true
+		return 1, nil
+	}
+	if err != nil && name == "version" && cmd == a.Root && a.Version != "" {
+		// Parse the default version flag of the root command like the
+		// default help flag
+		ctx.version = true
+		return 1, nil
+	}
+	if err != nil {
+		flag, err = a.flag(cmd, name)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	n := 1
+	value := fmt.Sprint(!negated)
+	if !flag.Bool {
+		if len(args) < 2 {
+			return 0, fmt.Errorf("no value found for flag: %s", name)
+		}
+
+		n = 2
+		value = args[1]
+		if a.ExpandEnvInFlags {
+			value = os.ExpandEnv(value)
+		}
+	}
+
+	if err := flag.checkAllowedValue(value); err != nil {
+		return 0, fmt.Errorf("flag --%s: %s", flag.Name, err)
+	}
+	if err := flag.validate(value); err != nil {
+		return 0, fmt.Errorf("invalid value for flag %s: %s", name, err)
+	}
+	if flag.Deprecated != "" {
+		warn(fmt.Sprintf("flag --%s is deprecated: %s", flag.Name, flag.Deprecated))
+	}
+	ctx.flags[flag.Name] = value
+	ctx.flagsPassed[flag.Name] = true
+	if flag.Multiple {
+		ctx.flagsMultiple[flag.Name] = append(ctx.flagsMultiple[flag.Name], value)
+	}
+	return n, nil
+}
+
+// isHelpFlag returns whether the given flag name is one of the default help
+// flag names.
+func isHelpFlag(name string) bool {
+	return name == "help" || name == "h"
+}
+
+// parseFlagName parses the given argument for a flag name, returning the name
+// and a flag whether it was found.
+func parseFlagName(arg string) (string, bool) {
+	matches := longFlagRegexp.FindStringSubmatch(arg)
+	if len(matches) > 1 {
+		return matches[1], true
+	}
+
+	matches = shortFlagRegexp.FindStringSubmatch(arg)
+	if len(matches) > 1 {
+		return matches[1], true
+	}
+
+	return "", false
+}
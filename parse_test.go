@@ -0,0 +1,216 @@
+package kubo_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ravernkoh/kubo"
+	"github.com/ravernkoh/kubo/kubotest"
+)
+
+func TestParseNoArguments(t *testing.T) {
+	root := &kubo.Command{Name: "root"}
+
+	if _, err := kubo.Parse(root, nil); err == nil {
+		t.Fatal("expected an error, got none")
+	}
+}
+
+func TestParseInvalidFlags(t *testing.T) {
+	root := &kubo.Command{
+		Name: "root",
+		Flags: []kubo.Flag{
+			{Name: "name", Required: true, Default: "x"},
+		},
+	}
+
+	if _, err := kubo.Parse(root, []string{"root"}); err == nil {
+		t.Fatal("expected an error, got none")
+	}
+}
+
+func TestParseAppRoot(t *testing.T) {
+	var stdout bytes.Buffer
+	root := &kubo.Command{Name: "root"}
+	app := kubo.NewApp(root)
+	app.Stdout = &stdout
+
+	if _, err := kubo.Parse(root, []string{"root"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if root.OutOrStdout() != &stdout {
+		t.Fatal("expected the stdout of the app")
+	}
+}
+
+func TestParseFlagsBeforeChild(t *testing.T) {
+	tests := []struct {
+		args  []string
+		flags map[string]string
+		valid bool
+	}{
+		{[]string{"root", "--verbose", "child"}, map[string]string{"verbose": "true", "level": "2"}, true},
+		{[]string{"root", "child", "--level", "5"}, map[string]string{"verbose": "false", "level": "5"}, true},
+		{[]string{"root", "--name", "x", "child"}, nil, false},
+		{[]string{"root", "--level", "5", "child"}, nil, false},
+	}
+
+	for _, test := range tests {
+		root := &kubo.Command{
+			Name: "root",
+			Flags: []kubo.Flag{
+				kubo.StringFlag("level", "the level", "1"),
+				kubo.StringFlag("name", "the name", ""),
+			},
+			PersistentFlags: []kubo.Flag{
+				kubo.BoolFlag("verbose", "be verbose"),
+			},
+		}
+		child := &kubo.Command{
+			Name: "child",
+			Flags: []kubo.Flag{
+				kubo.StringFlag("level", "the level", "2"),
+			},
+		}
+		root.Add(child)
+
+		res, err := kubo.Parse(root, test.args)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%v: expected an error, got none", test.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: expected no error, got: %v", test.args, err)
+			continue
+		}
+		if res.Command != child {
+			t.Errorf("%v: expected command child, got %s", test.args, res.Command.Name)
+		}
+		if !reflect.DeepEqual(res.Flags, test.flags) {
+			t.Errorf("%v: expected flags %v, got %v", test.args, test.flags, res.Flags)
+		}
+	}
+}
+
+func TestRunFlagInterspersed(t *testing.T) {
+	root := &kubo.Command{
+		Name:      "root",
+		Arguments: []kubo.Argument{{Name: "args", Multiple: true}},
+		Flags:     []kubo.Flag{kubo.BoolFlag("verbose", "be verbose")},
+		Run: func(ctx *kubo.Context) error {
+			args, err := ctx.ArgumentMultiple("args")
+			if err != nil {
+				return err
+			}
+			verbose, err := ctx.Flag("verbose")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(ctx.Stdout(), "%v %s\n", args, verbose)
+			return nil
+		},
+	}
+	app := kubo.NewApp(root)
+
+	kubotest.New(app).Run("a", "--verbose", "b").AssertStdout(t, "[a b] true\n")
+
+	app.FlagInterspersed = false
+	kubotest.New(app).Run("a", "--verbose", "b").AssertStdout(t, "[a --verbose b] false\n")
+}
+
+func TestParseCommandSuggestions(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"root", "statuss"}, "command not defined: statuss, did you mean: status?"},
+		{[]string{"root", "rmv"}, "command not defined: rmv, did you mean: remove?"},
+		{[]string{"root", "remote", "ad"}, "command not defined: ad, did you mean: add?"},
+		{[]string{"root", "xyz"}, "command not defined: xyz"},
+	}
+
+	root := &kubo.Command{Name: "root"}
+	root.Add(&kubo.Command{Name: "status"})
+	root.Add(&kubo.Command{Name: "remove", Aliases: []string{"rm"}})
+	remote := &kubo.Command{Name: "remote"}
+	remote.Add(&kubo.Command{Name: "add"})
+	root.Add(remote)
+
+	for _, test := range tests {
+		_, err := kubo.Parse(root, test.args)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("%v: expected error %q, got: %v", test.args, test.expected, err)
+		}
+	}
+}
+
+func TestParseOptionalArguments(t *testing.T) {
+	tests := []struct {
+		arguments []kubo.Argument
+		valid     bool
+	}{
+		{[]kubo.Argument{{Name: "a"}, {Name: "b", Optional: true}}, true},
+		{[]kubo.Argument{{Name: "a", Optional: true}, {Name: "b", Multiple: true, Optional: true}}, true},
+		{[]kubo.Argument{{Name: "a", Optional: true}, {Name: "b"}}, false},
+		{[]kubo.Argument{{Name: "a", Optional: true}, {Name: "b", Multiple: true}}, false},
+	}
+
+	for i, test := range tests {
+		root := &kubo.Command{Name: "root", Arguments: test.arguments}
+		_, err := kubo.Parse(root, []string{"root", "x"})
+		if test.valid && err != nil {
+			t.Errorf("test %d: expected no error, got: %v", i, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("test %d: expected an error, got none", i)
+		}
+	}
+}
+
+func TestRunOmittedMultipleArgument(t *testing.T) {
+	tests := []struct {
+		argument kubo.Argument
+		expected string
+	}{
+		{kubo.Argument{Name: "files", Optional: true, Multiple: true}, "[] 0\n"},
+		{kubo.Argument{Name: "files", Optional: true, Multiple: true, Default: "a"}, "[a] 1\n"},
+	}
+
+	for _, test := range tests {
+		root := &kubo.Command{
+			Name:      "root",
+			Arguments: []kubo.Argument{test.argument},
+			Run: func(ctx *kubo.Context) error {
+				files, err := ctx.ArgumentMultiple("files")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(ctx.Stdout(), "%v %d\n", files, len(files))
+				return nil
+			},
+		}
+
+		kubotest.New(kubo.NewApp(root)).Run().AssertStdout(t, test.expected)
+	}
+}
+
+func TestParseHiddenCommands(t *testing.T) {
+	root := &kubo.Command{Name: "root"}
+	root.Add(&kubo.Command{Name: "server", Hidden: true, Run: func(*kubo.Context) error { return nil }})
+
+	app := kubo.NewApp(root)
+	app.EnableAbbreviations = true
+	app.EnableCaseInsensitiveCommands = true
+
+	for _, args := range [][]string{{"serv"}, {"SERVER"}} {
+		res := kubotest.New(app).Run(args...)
+		if res.Err == nil {
+			t.Errorf("%v: expected an error, got none", args)
+		}
+	}
+	kubotest.New(app).Run("server").AssertExitOK(t)
+}